@@ -1,12 +1,71 @@
 import { defineConfig } from 'astro/config';
 import sitemap from '@astrojs/sitemap';
-import { readingTimePlugin } from './src/plugins/readingTimePlugin.js';
+import { devBuildAPI } from './src/integrations/devBuildAPI';
+import { imageOptimizer } from './src/integrations/imageOptimizer';
+import { mastodonSyndication } from './src/integrations/mastodonSyndication';
+import { outputManifest } from './src/integrations/outputManifest';
+import { pinnedFiles } from './src/integrations/pinnedFiles';
+import { redirectConfigs } from './src/integrations/redirectConfigs';
+import { templateContract } from './src/integrations/templateContract';
+import { themeFiles } from './src/integrations/themeFiles';
+import { urlPolicy } from './src/integrations/urlPolicy';
+import { getMarkdownConfig } from './src/utils/markdownConfig.js';
+import { getLastModifiedByPath } from './src/utils/postMetadata';
+import { loadPlugins } from './src/utils/plugins.js';
+import { syncRemoteContent } from './src/utils/remoteContent.js';
+import { THEME_DIRS } from './src/utils/themes';
+import { pageURL } from './src/utils/urls.js';
 import siteConfig from './site.config.mjs';
 
+const plugins = await loadPlugins(siteConfig.PLUGINS);
+
+// Before the content layer looks for posts in them.
+await syncRemoteContent(siteConfig.REMOTE_CONTENT);
+
 export default defineConfig({
   site: siteConfig.SITE_URL,
-  integrations: [sitemap()],
-  markdown: {
-    remarkPlugins: [readingTimePlugin],
+  base: siteConfig.BASE_PATH || undefined,
+  outDir: siteConfig.OUT_DIR,
+  integrations: [
+    siteConfig.SITEMAP && sitemap({
+      serialize(item) {
+        // Posts pinned to a file name are listed as <file>/ like other pages.
+        item.url = item.url.replace(/\.html\/$/, '.html');
+        const lastModified = getLastModifiedByPath(new URL(item.url).pathname);
+        if (lastModified) item.lastmod = lastModified.toISOString();
+        item.url = pageURL(item.url);
+        return item;
+      },
+    }),
+    // Before everything that reads the output, so it sees pinned pages
+    // where they belong.
+    pinnedFiles(),
+    siteConfig.DEV_BUILD_API && devBuildAPI(),
+    templateContract({ strict: siteConfig.STRICT_TEMPLATES }),
+    urlPolicy({ site: siteConfig.SITE_URL }),
+    redirectConfigs(siteConfig.REDIRECT_CONFIGS),
+    THEME_DIRS.length > 0 && themeFiles(THEME_DIRS),
+    (siteConfig.IMAGE_MAX_WIDTH || siteConfig.IMAGE_FORMATS.length > 0) && imageOptimizer({
+      maxWidth: siteConfig.IMAGE_MAX_WIDTH,
+      quality: siteConfig.IMAGE_QUALITY,
+      formats: siteConfig.IMAGE_FORMATS,
+    }),
+    ...plugins.integrations,
+    // After every integration that checks or writes the output: posting is
+    // public and can't be taken back, so a build that fails a check (or a
+    // broken link in urlPolicy) must not have announced posts that were
+    // never deployed.
+    siteConfig.MASTODON_POST_INSTANCE && mastodonSyndication(),
+    // Last, so it sees the final output.
+    siteConfig.OUTPUT_MANIFEST && outputManifest(siteConfig.OUTPUT_MANIFEST),
+  ],
+  build: {
+    concurrency: siteConfig.BUILD_CONCURRENCY,
+    // Every page is written as <path>/index.html, which is what lets wiki
+    // links, feeds, the sitemap and the search index all use extensionless
+    // `/blog/<post>/` URLs (or `/blog/<post>/index.html`, see URL_STYLE).
+    // Astro's default, pinned so it can't drift.
+    format: 'directory',
   },
+  markdown: getMarkdownConfig(plugins),
 });
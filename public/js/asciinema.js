@@ -0,0 +1,250 @@
+// Minimal asciicast v2 player for casts embedded by the asciinema block plugin.
+// It replays output events into a <pre>, handling the cursor movement and
+// erase sequences common in shell sessions and SGR colors and attributes
+// (the 16 named colors, the 256-color palette and 24-bit colors).
+(function() {
+    const SEQUENCE = /\x1b\[([0-9;?]*)([A-Za-z])|\x1b\][^\x07]*\x07|\x1b./g;
+    const MAX_IDLE = 2;
+    const DEFAULT_STYLE = {};
+
+    // A color from the 256-color palette as CSS. The 16 named colors come
+    // from --ansi-<n> in the stylesheet, so themes can adjust them.
+    const paletteColor = (n) => {
+        if (n < 16) return `var(--ansi-${n})`;
+        if (n >= 232) {
+            const gray = 8 + (n - 232) * 10;
+            return `rgb(${gray}, ${gray}, ${gray})`;
+        }
+        const level = (value) => value === 0 ? 0 : 55 + value * 40;
+        const index = n - 16;
+        return `rgb(${level(Math.floor(index / 36))}, ${level(Math.floor(index / 6) % 6)}, ${level(index % 6)})`;
+    };
+
+    // Apply the parameters of an SGR (`ESC [ ... m`) sequence to a style.
+    // Styles are never changed in place, so cells can share them.
+    const applySGR = (style, args) => {
+        let next = { ...style };
+        for (let i = 0; i < args.length; i++) {
+            const code = args[i];
+            if (code === 0) next = {};
+            else if (code === 1) next.bold = true;
+            else if (code === 2) next.dim = true;
+            else if (code === 3) next.italic = true;
+            else if (code === 4) next.underline = true;
+            else if (code === 7) next.inverse = true;
+            else if (code === 22) next.bold = next.dim = false;
+            else if (code === 23) next.italic = false;
+            else if (code === 24) next.underline = false;
+            else if (code === 27) next.inverse = false;
+            else if (code >= 30 && code <= 37) next.fg = paletteColor(code - 30);
+            else if (code >= 90 && code <= 97) next.fg = paletteColor(code - 90 + 8);
+            else if (code === 39) next.fg = undefined;
+            else if (code >= 40 && code <= 47) next.bg = paletteColor(code - 40);
+            else if (code >= 100 && code <= 107) next.bg = paletteColor(code - 100 + 8);
+            else if (code === 49) next.bg = undefined;
+            else if (code === 38 || code === 48) {
+                // 38;5;<n> picks from the palette, 38;2;<r>;<g>;<b> is 24-bit.
+                let color;
+                if (args[i + 1] === 5) {
+                    color = paletteColor(args[i + 2] & 255);
+                    i += 2;
+                } else if (args[i + 1] === 2) {
+                    color = `rgb(${args[i + 2] & 255}, ${args[i + 3] & 255}, ${args[i + 4] & 255})`;
+                    i += 4;
+                }
+                if (color) next[code === 38 ? 'fg' : 'bg'] = color;
+            }
+        }
+        return next;
+    };
+
+    const escapeHtml = (text) => text.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+
+    const renderRun = (text, style) => {
+        if (style === DEFAULT_STYLE) return escapeHtml(text);
+        const fg = style.inverse ? (style.bg || 'var(--bg-color)') : style.fg;
+        const bg = style.inverse ? (style.fg || 'var(--text-color)') : style.bg;
+        const css = [
+            fg && `color: ${fg}`,
+            bg && `background-color: ${bg}`,
+            style.bold && 'font-weight: bold',
+            style.dim && 'opacity: 0.6',
+            style.italic && 'font-style: italic',
+            style.underline && 'text-decoration: underline',
+        ].filter(Boolean).join('; ');
+        return css ? `<span style="${css}">${escapeHtml(text)}</span>` : escapeHtml(text);
+    };
+
+    const parseCast = (text) => {
+        const lines = text.split('\n').filter(line => line.trim());
+        const header = JSON.parse(lines[0]);
+        const events = lines.slice(1)
+            .map(line => JSON.parse(line))
+            .filter(event => event[1] === 'o');
+        return { header, events };
+    };
+
+    const createTerminal = (rows) => {
+        // Each line is a list of cells; gaps left by cursor movement are empty.
+        const state = { lines: [[]], row: 0, col: 0, style: DEFAULT_STYLE };
+
+        const put = (char) => {
+            state.lines[state.row][state.col] = { char, style: state.style };
+            state.col++;
+        };
+
+        const moveTo = (row, col) => {
+            state.row = Math.max(0, row);
+            state.col = Math.max(0, col);
+            while (state.lines.length <= state.row) state.lines.push([]);
+        };
+
+        // Blank the current line up to and including the cursor's cell.
+        const eraseToCursor = () => {
+            const line = state.lines[state.row];
+            for (let col = 0; col <= state.col && col < line.length; col++) delete line[col];
+        };
+
+        const control = (params, command) => {
+            const args = params.replace('?', '').split(';').map(n => parseInt(n, 10) || 0);
+            const n = Math.max(1, args[0]);
+            const top = Math.max(0, state.lines.length - rows);
+            switch (command) {
+                case 'A': moveTo(state.row - n, state.col); break;
+                case 'B': moveTo(state.row + n, state.col); break;
+                case 'C': moveTo(state.row, state.col + n); break;
+                case 'D': moveTo(state.row, state.col - n); break;
+                case 'G': moveTo(state.row, n - 1); break;
+                case 'H':
+                case 'f': moveTo(top + Math.max(1, args[0]) - 1, Math.max(1, args[1] || 1) - 1); break;
+                case 'J':
+                    if (args[0] >= 2) {
+                        state.lines = [[]];
+                        moveTo(0, 0);
+                    } else if (args[0] === 1) {
+                        for (let row = top; row < state.row; row++) state.lines[row] = [];
+                        eraseToCursor();
+                    } else {
+                        state.lines[state.row] = state.lines[state.row].slice(0, state.col);
+                        state.lines.length = state.row + 1;
+                    }
+                    break;
+                case 'K':
+                    if (args[0] === 0) state.lines[state.row] = state.lines[state.row].slice(0, state.col);
+                    else if (args[0] === 1) eraseToCursor();
+                    else state.lines[state.row] = [];
+                    break;
+                case 'm':
+                    state.style = params.startsWith('?') ? state.style : applySGR(state.style, args);
+                    if (Object.values(state.style).every(value => !value)) state.style = DEFAULT_STYLE;
+                    break;
+            }
+        };
+
+        // The screen as HTML, with one span per run of equally styled cells.
+        const html = () => state.lines.map(line => {
+            let out = '';
+            let run = '';
+            let runStyle = DEFAULT_STYLE;
+            for (let col = 0; col < line.length; col++) {
+                const cell = line[col] || { char: ' ', style: DEFAULT_STYLE };
+                if (cell.style !== runStyle) {
+                    out += renderRun(run, runStyle);
+                    run = '';
+                    runStyle = cell.style;
+                }
+                run += cell.char;
+            }
+            return out + renderRun(run, runStyle);
+        }).join('\n');
+
+        const write = (data) => {
+            let last = 0;
+            const text = (chunk) => {
+                for (const char of chunk) {
+                    if (char === '\n') moveTo(state.row + 1, 0);
+                    else if (char === '\r') state.col = 0;
+                    else if (char === '\b') state.col = Math.max(0, state.col - 1);
+                    else if (char >= ' ') put(char);
+                }
+            };
+            data.replace(SEQUENCE, (match, params, command, offset) => {
+                text(data.slice(last, offset));
+                if (command) control(params, command);
+                last = offset + match.length;
+                return match;
+            });
+            text(data.slice(last));
+        };
+
+        return { write, html };
+    };
+
+    const initPlayer = (player) => {
+        if (player.dataset.ready) return;
+        player.dataset.ready = 'true';
+
+        const screen = player.querySelector('.asciinema-screen');
+        const toggle = player.querySelector('.asciinema-toggle');
+        const source = player.querySelector('script[type="application/x-asciicast"]');
+        if (!screen || !toggle || !source) return;
+
+        const { header, events } = parseCast(source.textContent);
+        if (events.length === 0) {
+            toggle.hidden = true;
+            return;
+        }
+        const idleLimit = header.idle_time_limit || MAX_IDLE;
+        screen.style.setProperty('--cast-cols', header.width || 80);
+        screen.style.setProperty('--cast-rows', header.height || 24);
+
+        let terminal;
+        let index = 0;
+        let timer = null;
+
+        const reset = () => {
+            terminal = createTerminal(header.height || 24);
+            index = 0;
+            screen.textContent = '';
+        };
+
+        const stop = (label) => {
+            clearTimeout(timer);
+            timer = null;
+            toggle.textContent = label;
+        };
+
+        const step = () => {
+            const event = events[index];
+            terminal.write(event[2]);
+            screen.innerHTML = terminal.html();
+            screen.scrollTop = screen.scrollHeight;
+            index++;
+
+            if (index >= events.length) {
+                stop('↻ Replay');
+                return;
+            }
+            const delay = Math.min(events[index][0] - event[0], idleLimit);
+            timer = setTimeout(step, Math.max(0, delay) * 1000);
+        };
+
+        toggle.addEventListener('click', () => {
+            if (timer) {
+                stop('▶ Play');
+                return;
+            }
+            if (!terminal || index >= events.length) reset();
+            toggle.textContent = '❚❚ Pause';
+            step();
+        });
+    };
+
+    const initAll = () => document.querySelectorAll('.asciinema-player').forEach(initPlayer);
+
+    if (document.readyState === 'loading') {
+        document.addEventListener('DOMContentLoaded', initAll);
+    } else {
+        initAll();
+    }
+})();
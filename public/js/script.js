@@ -3,22 +3,30 @@ const loadThemeCSS = (theme) => {
     if (!theme) return;
     
     const themeId = `theme-css-${theme}`;
-    if (!document.getElementById(themeId)) {
+    const existing = document.getElementById(themeId);
+    if (existing) {
+        // The light default's stylesheet only applies to light systems
+        // until it is chosen.
+        existing.removeAttribute('media');
+    } else {
         const link = document.createElement('link');
         link.id = themeId;
         link.rel = 'stylesheet';
-        link.href = `/css/themes/${theme}.css`;
+        const version = document.body.dataset.assetVersion;
+        link.href = `${document.body.dataset.base || ''}/css/themes/${theme}.css${version ? `?v=${version}` : ''}`;
         document.head.appendChild(link);
     }
 };
 
-// Apply theme immediately to prevent flash
+// Re-apply the saved theme. ThemeScript has normally done this before first
+// paint already; this covers pages rendered without it.
 (function() {
     const body = document.body;
-    const schemes = ['mocha', 'frappe', 'latte', 'macchiato', 'gruvbox', 'nord', 'tokyonight', 'monokai', 'onedark', 'solarized', 'kanagawa', 'pinkie'];
+    const schemes = (body.dataset.themes || '').split(',');
+    const defaultScheme = body.dataset.defaultTheme;
     
     // Store the original server-set theme
-    const originalServerTheme = body.getAttribute('data-theme') || 'pinkie';
+    const originalServerTheme = body.getAttribute('data-theme') || defaultScheme;
     
     // Check for saved theme preference first
     let savedScheme = localStorage.getItem('colorScheme');
@@ -26,7 +34,7 @@ const loadThemeCSS = (theme) => {
         savedScheme = originalServerTheme;
     }
     if (!savedScheme || !schemes.includes(savedScheme)) {
-        savedScheme = 'pinkie';
+        savedScheme = defaultScheme;
     }
     
     // Apply the theme immediately
@@ -35,8 +43,8 @@ const loadThemeCSS = (theme) => {
         loadThemeCSS(savedScheme);
     }
     
-    // Apply light-theme class if using latte
-    if (savedScheme === 'latte') {
+    // Apply light-theme class if using a light scheme
+    if ((body.dataset.lightThemes || '').split(',').includes(savedScheme)) {
         body.classList.add('light-theme');
     }
 })();
@@ -57,8 +65,11 @@ document.addEventListener('DOMContentLoaded', function() {
     const body = document.body;
     const terminal = document.querySelector('.terminal');
     
-    // Color schemes available
-    const schemes = ['mocha', 'frappe', 'latte', 'macchiato', 'gruvbox', 'nord', 'tokyonight', 'monokai', 'onedark', 'solarized', 'kanagawa', 'pinkie'];
+    // Color schemes available, as the build found them
+    const schemes = (body.dataset.themes || '').split(',');
+    const lightSchemes = (body.dataset.lightThemes || '').split(',');
+    const defaultScheme = body.dataset.defaultTheme;
+    const lightScheme = body.dataset.lightTheme || 'latte';
 
     const updateAccessibilityButtonState = () => {
         if (!accessibilityButton) return;
@@ -309,9 +320,6 @@ document.addEventListener('DOMContentLoaded', function() {
     // Theme toggle functionality
     const themeButton = document.getElementById('theme-button');
 
-    // Get the original server-set theme (already stored in IIFE)
-    const originalServerTheme = body.getAttribute('data-theme') || 'pinkie';
-
     const updateThemeButtonState = () => {
         if (!themeButton) return;
         const isLight = body.classList.contains('light-theme');
@@ -337,7 +345,7 @@ document.addEventListener('DOMContentLoaded', function() {
     };
     
     // Get the current scheme (already applied by inline script)
-    const savedScheme = localStorage.getItem('colorScheme') || body.getAttribute('data-theme') || 'pinkie';
+    const savedScheme = localStorage.getItem('colorScheme') || body.getAttribute('data-theme') || defaultScheme;
     if (themeSelect) {
         themeSelect.value = savedScheme;
     }
@@ -351,7 +359,7 @@ document.addEventListener('DOMContentLoaded', function() {
             applyScheme(selectedScheme);
             
             // Update light-theme class based on scheme
-            if (selectedScheme === 'latte') {
+            if (lightSchemes.includes(selectedScheme)) {
                 body.classList.add('light-theme');
                 localStorage.setItem('theme', 'light');
             } else {
@@ -367,22 +375,90 @@ document.addEventListener('DOMContentLoaded', function() {
     if (themeButton) {
         themeButton.addEventListener('click', function() {
             if (body.classList.contains('light-theme')) {
-                // Switch to dark mode (use original server theme
+                // Switch to dark mode (the default theme)
                 body.classList.remove('light-theme');
-                applyScheme(originalServerTheme);
+                applyScheme(defaultScheme);
                 localStorage.setItem('theme', 'dark');
-                if (themeSelect) themeSelect.value = originalServerTheme;
+                if (themeSelect) themeSelect.value = defaultScheme;
             } else {
-                // Switch to light mode (latte)
+                // Switch to light mode (the light default, or latte)
                 body.classList.add('light-theme');
-                applyScheme('latte');
+                applyScheme(lightScheme);
                 localStorage.setItem('theme', 'light');
-                if (themeSelect) themeSelect.value = 'latte';
+                if (themeSelect) themeSelect.value = lightScheme;
             }
             updateThemeButtonState();
         });
     }
     
+    // Reading progress and scroll-spy, driven by the heading list the page
+    // embeds so we don't have to guess which headings belong to the post
+    const headingData = document.getElementById('page-headings');
+    const article = document.querySelector('.blog-post');
+    if (headingData && article) {
+        const progressBar = document.querySelector('.reading-progress');
+        const headings = JSON.parse(headingData.textContent)
+            .map(heading => document.getElementById(heading.id))
+            .filter(Boolean);
+
+        const updateReadingPosition = () => {
+            const rect = article.getBoundingClientRect();
+            const scrollable = rect.height - window.innerHeight;
+            const progress = scrollable > 0 ? Math.min(Math.max(-rect.top / scrollable, 0), 1) : 1;
+            if (progressBar) progressBar.style.transform = `scaleX(${progress})`;
+
+            // The current section is the last heading scrolled past
+            let current = null;
+            for (const heading of headings) {
+                if (heading.getBoundingClientRect().top > 80) break;
+                current = heading;
+            }
+            document.querySelectorAll('a[href^="#"].active').forEach(link => link.classList.remove('active'));
+            if (current) {
+                document.querySelectorAll(`a[href="#${CSS.escape(current.id)}"]`).forEach(link => link.classList.add('active'));
+            }
+        };
+
+        window.addEventListener('scroll', updateReadingPosition, { passive: true });
+        window.addEventListener('resize', updateReadingPosition);
+        updateReadingPosition();
+    }
+    
+    // Copy buttons for code blocks, copying the raw source the build stores
+    // in data-code rather than the highlighted markup
+    document.querySelectorAll('[data-code]').forEach(wrapper => {
+        const button = document.createElement('button');
+        button.type = 'button';
+        button.className = 'code-copy';
+        button.textContent = 'Copy';
+        button.setAttribute('aria-label', 'Copy code to clipboard');
+        button.addEventListener('click', () => {
+            navigator.clipboard.writeText(wrapper.dataset.code).then(() => {
+                button.textContent = 'Copied!';
+                setTimeout(() => { button.textContent = 'Copy'; }, 1500);
+            });
+        });
+
+        const header = wrapper.querySelector('.code-header');
+        if (header) {
+            header.appendChild(button);
+        } else {
+            wrapper.classList.add('code-copy-floating');
+            wrapper.prepend(button);
+        }
+    });
+    
+    // Copy-link share buttons
+    document.querySelectorAll('.share-copy').forEach(button => {
+        button.addEventListener('click', function() {
+            navigator.clipboard.writeText(this.dataset.url).then(() => {
+                const label = this.textContent;
+                this.textContent = 'Copied!';
+                setTimeout(() => { this.textContent = label; }, 1500);
+            });
+        });
+    });
+    
     // Blog search functionality
     const searchInput = document.getElementById('search-input');
     const noResults = document.getElementById('no-results');
@@ -561,7 +637,7 @@ document.addEventListener('DOMContentLoaded', function() {
             // Get current scheme from data attribute
             let currentScheme = body.getAttribute('data-theme');
             if (!currentScheme || !schemes.includes(currentScheme)) {
-                currentScheme = 'pinkie';
+                currentScheme = defaultScheme;
             }
 
             // Get available schemes excluding current one
@@ -0,0 +1,64 @@
+// Submit new and changed posts to the Wayback Machine.
+//
+// Run after a deploy (`bun run archive`). Reads the deployed search index so
+// it only needs the live site, and remembers what it already submitted in
+// WAYBACK_STATE_FILE so each post revision is archived once.
+
+import { readFileSync, writeFileSync } from 'fs';
+import siteConfig from '../site.config.mjs';
+import { blogURL } from '../src/utils/urls.js';
+import { OFFLINE, request } from '../src/utils/http.js';
+
+const SAVE_ENDPOINT = 'https://web.archive.org/save/';
+
+function readState() {
+  try {
+    return JSON.parse(readFileSync(siteConfig.WAYBACK_STATE_FILE, 'utf-8'));
+  } catch {
+    return {};
+  }
+}
+
+function writeState(state) {
+  writeFileSync(siteConfig.WAYBACK_STATE_FILE, `${JSON.stringify(state, null, 2)}\n`);
+}
+
+function sleep(seconds) {
+  return new Promise(resolve => setTimeout(resolve, seconds * 1000));
+}
+
+if (OFFLINE) {
+  console.error('[archive] offline, nothing to do');
+  process.exit(1);
+}
+
+const response = await request(new URL(blogURL('search-index.json'), siteConfig.SITE_URL));
+if (!response.ok) {
+  console.error(`[archive] could not fetch the search index: ${response.status}`);
+  process.exit(1);
+}
+
+const posts = await response.json();
+const state = readState();
+const pending = posts
+  .map(post => ({ url: new URL(post.url, siteConfig.SITE_URL).href, revision: post.lastModified || post.date }))
+  .filter(post => state[post.url] !== post.revision);
+
+console.log(`[archive] ${pending.length} of ${posts.length} posts need a snapshot`);
+
+for (const [index, post] of pending.entries()) {
+  // The save API throttles anonymous clients hard; stay well under it.
+  if (index > 0) await sleep(siteConfig.WAYBACK_DELAY);
+
+  try {
+    // Snapshots are taken while the request waits, which can take a while.
+    const result = await request(SAVE_ENDPOINT + post.url, { redirect: 'manual' }, { timeout: 120 * 1000 });
+    if (result.status >= 400) throw new Error(`status ${result.status}`);
+
+    state[post.url] = post.revision;
+    writeState(state);
+    console.log(`[archive] saved ${post.url}`);
+  } catch (error) {
+    console.warn(`[archive] could not save ${post.url}: ${error.message}`);
+  }
+}
@@ -0,0 +1,32 @@
+// Render markdown from stdin to HTML on stdout, through the same markdown
+// pipeline as the site (highlighting, diagrams, tables, ...), so editors can
+// preview exactly what a build produces:
+//
+//   bun run render < post.md
+//   bun run render --path src/content/blog/Nim/post.md < post.md
+//
+// --path tells plugins that read neighbouring files (csv src=, asciinema
+// casts) where the post lives. The output is the article body only.
+
+import { createMarkdownProcessor } from '@astrojs/markdown-remark';
+import { pathToFileURL } from 'url';
+import { resolve } from 'path';
+import { getMarkdownConfig } from '../src/utils/markdownConfig.js';
+import { loadPlugins } from '../src/utils/plugins.js';
+import siteConfig from '../site.config.mjs';
+
+const pathIndex = process.argv.indexOf('--path');
+const path = pathIndex === -1 ? undefined : process.argv[pathIndex + 1];
+
+let source = '';
+for await (const chunk of process.stdin) source += chunk;
+
+// Frontmatter and comment metadata are content-layer concerns, not markup.
+const body = source.replace(/^---\r?\n[\s\S]*?\r?\n---\r?\n/, '');
+
+const processor = await createMarkdownProcessor(getMarkdownConfig(await loadPlugins(siteConfig.PLUGINS)));
+const { code } = await processor.render(body, {
+  fileURL: pathToFileURL(resolve(path ?? 'stdin.md')),
+});
+
+process.stdout.write(`${code}\n`);
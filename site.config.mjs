@@ -8,18 +8,306 @@ const siteConfig = {
   // Base URL for the site (used for RSS feeds, sitemap, and absolute links).
   SITE_URL: 'https://krea.to',
 
-  // Default theme for the website.
+  // Author of posts that don't name one.
+  DEFAULT_AUTHOR: 'Kreato',
+
+  // Path the site is served under when it doesn't live at the domain root,
+  // e.g. '/notes' for https://example.com/notes/. Every link the site
+  // generates includes it; links written by hand in posts must too.
+  BASE_PATH: '',
+
+  // URL directory posts, tags and feeds are published under: 'blog' gives
+  // /blog/<post>/ and /blog/feed.xml. One path segment, e.g. 'posts'.
+  BLOG_PATH: 'blog',
+
+  // How links to pages are written: 'directory' for /blog/<post>/, or 'file'
+  // for /blog/<post>/index.html (for hosts that don't serve index files).
+  // Feeds, the sitemap and canonical URLs follow it, and the build warns
+  // about links in posts that don't.
+  URL_STYLE: 'directory',
+
+  // Generate sitemap-index.xml and reference it from robots.txt.
+  // true to enable, false to disable
+  SITEMAP: true,
+
+  // Directories containing blog posts, relative to the project root.
+  // Each root may live in its own git repository (e.g. a submodule); commit
+  // info is always read from the repository that actually contains the file.
+  CONTENT_ROOTS: ['src/content/blog'],
+
+  // Content kept in other repositories, fetched at the start of every build
+  // into `root` and read like CONTENT_ROOTS. A source is a git repository
+  // ({ root, git: url, ref: branch, tag or commit }) or a .tar.gz archive
+  // ({ root, archive: url, sha256 }). A commit hash or sha256 pins the
+  // content and lets builds reuse what they fetched before. Add the roots to
+  // .gitignore.
+  REMOTE_CONTENT: [],
+
+  // Directory the build writes the site to, relative to the project root.
+  // It is never read as content, even when a content root contains it.
+  OUT_DIR: 'dist',
+
+  // Number of pages rendered in parallel during a build. Raise it for sites
+  // with hundreds of posts; output (including feeds and indexes) is the same
+  // whatever the value.
+  BUILD_CONCURRENCY: 1,
+
+  // Metadata every blog post must set, e.g. ['title', 'date']. Posts missing
+  // one fail the build (and `astro check`). Unknown keys always fail.
+  REQUIRED_METADATA: [],
+
+  // Default theme for the website. THEME=<name> in the environment picks
+  // another for one build.
   // Available themes: nord, latte, frappe, mocha, macchiato, gruvbox,
   // tokyonight, monokai, onedark, solarized, kanagawa, pinkie
   DEFAULT_THEME: 'pinkie',
 
+  // Theme shown instead of DEFAULT_THEME to visitors whose system is set to
+  // a light color scheme, until they choose one from the menu, e.g. 'latte'.
+  // Leave empty to show DEFAULT_THEME to everyone.
+  DEFAULT_LIGHT_THEME: '',
+
+  // Directories laid out like public/, relative to the project root, whose
+  // files are published over the site's own: css/themes/<name>.css adds a
+  // theme, css/style.css replaces the stylesheet. Earlier directories win.
+  THEME_DIRS: [],
+
+  // Markup wrapped around highlighted code blocks in posts.
+  // The wrapper carries a data-lang attribute with the block's language.
+  CODE_WRAPPER_ELEMENT: 'div',
+  CODE_WRAPPER_CLASS: 'code-wrapper',
+
+  // Shiki theme for code blocks (https://shiki.style/themes). 'css-variables'
+  // colors code from the active color scheme instead, so it follows theme
+  // switches; see the --astro-code-* variables in public/css/style.css.
+  CODE_THEME: 'github-dark',
+
+  // Render the code block language in a header bar above each block.
+  // true to enable, false to disable
+  CODE_LANGUAGE_LABEL: true,
+
+  // KaTeX's dist/ directory, for posts with math: its stylesheet, and its
+  // scripts when the katex package isn't installed to render at build time.
+  // A CDN, or a path below public/ like '/katex' to host a copy yourself.
+  KATEX_URL: 'https://cdn.jsdelivr.net/npm/katex@0.16.22/dist',
+
+  // RSS channel metadata. FEED_COPYRIGHT falls back to COPYRIGHT when empty;
+  // FEED_TTL is in minutes. Leave a value empty to omit it.
+  // FEED_LIMIT caps the number of posts in the feeds, newest first, e.g. 20
+  // for large blogs; 0 keeps every post.
+  FEED_LIMIT: 0,
+  FEED_DESCRIPTION: 'Blog Posts and Articles by Kreato',
+  FEED_LANGUAGE: 'en-us',
+  FEED_COPYRIGHT: '',
+  FEED_CATEGORY: '',
+  FEED_TTL: 60,
+
+  // Include each post's full rendered HTML in the feed (content:encoded)
+  // instead of only its description.
+  // true to enable, false to disable
+  FEED_FULL_CONTENT: false,
+
+  // Number of posts in the recently updated feed (/blog/updates.xml).
+  UPDATES_FEED_LIMIT: 20,
+
+  // Posts without a `description` or a `<!-- more -->` marker are summarized
+  // by their first SUMMARY_WORDS words on listings and in feeds. 0 to leave
+  // them without a summary.
+  SUMMARY_WORDS: 0,
+
+  // Reading time estimate shown with each post. READING_SPEED is in words
+  // per minute; {minutes} in the format is replaced with the estimate, so
+  // the label can be translated (e.g. 'Lesezeit: {minutes} Min.').
+  READING_SPEED: 200,
+  READING_TIME_FORMAT: '~{minutes} min read',
+
+  // Show a reading progress bar on blog posts.
+  // true to enable, false to disable
+  READING_PROGRESS: true,
+
+  // Link each post to the next older and newer post, in the blog index's
+  // date order.
+  // true to enable, false to disable
+  POST_NAVIGATION: true,
+
+  // List the posts that link to a post with [[wiki links]] below it.
+  // true to enable, false to disable
+  BACKLINKS: true,
+
+  // List up to RELATED_POSTS posts below a post that share at least
+  // RELATED_POSTS_MIN_SHARED_TAGS of its tags, the most shared tags first.
+  // 0 to hide the list.
+  RELATED_POSTS: 3,
+  RELATED_POSTS_MIN_SHARED_TAGS: 1,
+
+  // Let ![[file]] embeds match files whose name differs only in case, with
+  // a warning. Links to posts ([[Post Name]]) ignore case either way.
+  // true to enable, false to disable
+  CASE_INSENSITIVE_LINKS: false,
+
+  // Drop a post's leading H1 from its body, since the layout already shows
+  // the title above it. Posts without a title or a descriptive file name
+  // (like index.md) take their title from that H1 either way.
+  // true to enable, false to disable
+  STRIP_TITLE_H1: false,
+
+  // Demote the headings in post bodies by this many levels (1 turns # into
+  // an H2, ## into an H3), for posts written with their own H1 sections.
+  // Levels stop at H6. 0 keeps them as written.
+  HEADING_OFFSET: 0,
+
+  // Taxonomies whose terms get a feed next to their page, e.g.
+  // /blog/tags/<tag>/feed.xml or /blog/<category>/feed.xml. Available:
+  // 'tags', 'categories', 'series', 'authors'.
+  TAXONOMY_FEEDS: ['tags', 'categories'],
+
+  // Number of most used tags listed on the blog index and the landing page.
+  // 0 to hide them.
+  POPULAR_TAGS: 10,
+
+  // Images used in posts are scaled down to IMAGE_MAX_WIDTH pixels and
+  // re-encoded at IMAGE_QUALITY (1-100) after the build. Set the width to 0
+  // to ship originals untouched.
+  IMAGE_MAX_WIDTH: 1600,
+  IMAGE_QUALITY: 80,
+
+  // Modern formats JPEG and PNG post images are also published in, served
+  // through <picture> with the original as fallback: 'webp' and/or 'avif'.
+  // AVIF files are smaller but slow to encode. [] to disable.
+  IMAGE_FORMATS: ['webp'],
+
+  // Print stylesheet for readers saving posts as PDF. PRINT_PAGES also
+  // publishes a printer-friendly copy of every post at /blog/<post>/print/.
+  // true to enable, false to disable
+  PRINT_STYLESHEET: true,
+  PRINT_PAGES: false,
+
+  // Generate a social preview card (title, site name and date over the
+  // default theme's wallpaper) at /blog/<post>/og.png for posts without an
+  // `image`. OG_CARD_BACKGROUND overrides the wallpaper with an image below
+  // public/, e.g. '/assets/card.png'. Needs the sharp package.
+  // true to enable, false to disable
+  OG_CARDS: false,
+  OG_CARD_BACKGROUND: '',
+
+  // Mastodon instance used for the "Share" link on blog posts.
+  MASTODON_SHARE_INSTANCE: 'mastodon.social',
+
+  // Announce new posts on this Mastodon instance after each build, using the
+  // account behind the MASTODON_TOKEN environment variable. Status URLs are
+  // saved to SYNDICATION_STATE_FILE and linked from the posts on the next
+  // build. Leave empty to disable.
+  MASTODON_POST_INSTANCE: '',
+  SYNDICATION_STATE_FILE: 'syndication.json',
+
+  // Wayback Machine submissions made by `bun run archive` after a deploy.
+  // WAYBACK_DELAY is the pause between submissions, in seconds.
+  WAYBACK_DELAY: 20,
+  WAYBACK_STATE_FILE: '.wayback.json',
+
+  // Redirects for moved pages, one `from: to` per line (see
+  // src/utils/redirects.ts). Each exact path gets a redirect page; for hosts
+  // that support it, REDIRECT_CONFIGS also writes real redirect rules:
+  // 'netlify' (_redirects, also read by Cloudflare Pages) and 'nginx'
+  // (redirects.nginx.conf to include in a server block).
+  REDIRECTS_FILE: 'redirects.yaml',
+  REDIRECT_CONFIGS: [],
+
+  // Publish each post's rendered body without any layout at
+  // /blog/<post>/fragment.html, for embedding posts into other sites.
+  // true to enable, false to disable
+  FRAGMENTS: false,
+
+  // Publish a dev.to-flavored copy of each post at /blog/<post>.devto.md for
+  // cross-posting. The copy points its canonical_url back at this site.
+  // true to enable, false to disable
+  DEVTO_EXPORT: false,
+
+  // GitHub Discussions used by giscus ("owner/repo"), for showing comment
+  // counts on post listings. Needs GITHUB_TOKEN at build time; counts are
+  // cached for COMMENTS_CACHE_TTL minutes. Leave empty to disable.
+  COMMENTS_REPO: '',
+  COMMENTS_CATEGORY: 'Announcements',
+  COMMENTS_CACHE_TTL: 60,
+
+  // Footer shown on blog pages. Leave a value empty to hide that part.
+  COPYRIGHT: '© Kreato',
+  LICENSE_NAME: 'AGPL-3.0',
+  LICENSE_URL: 'https://www.gnu.org/licenses/agpl-3.0.html',
+
+  // Show an "Edit this page" link to the post's source in the footer.
+  // true to enable, false to disable
+  SHOW_EDIT_LINK: true,
+
   // Show commit info links on blog pages.
   // true to enable, false to disable
   SHOW_COMMIT_INFO: true,
 
+  // Append ?v=<hash of public/css and public/js> to stylesheet and script
+  // URLs, so browsers fetch them again after a deploy that changed them.
+  // true to enable, false to disable
+  ASSET_VERSIONING: true,
+
+  // Write a list of every built file with its size and SHA-256 to this
+  // file in the output, e.g. 'manifest.json', for verifying deploys.
+  // Leave empty to disable.
+  OUTPUT_MANIFEST: '',
+
+  // Build without network access: steps that fetch data (comment counts)
+  // use what they cached on earlier builds, and Mastodon syndication is
+  // skipped. OFFLINE=1 in the environment does the same for one build.
+  // true to enable, false to disable
+  OFFLINE: false,
+
+  // Let the dev server run full builds on `POST /__krea/build` and answer
+  // with a JSON build report, for editor plugins and external watchers.
+  // true to enable, false to disable
+  DEV_BUILD_API: false,
+
+  // Every built page is checked for <html lang>, charset, viewport and
+  // <title>. Missing ones are warned about; set this to fail the build instead.
+  // true to enable, false to disable
+  STRICT_TEMPLATES: false,
+
+  // Link lists available to layouts as Astro.locals.site.menus, by name.
+  // URLs are site paths like '/blog/'; BASE_PATH is added to them. The
+  // 'main' menu is shown next to the back button of blog listings, the
+  // 'footer' menu in the footer of every blog page.
+  MENUS: {
+    main: [],
+    footer: [],
+  },
+
+  // Free-form values for custom layouts, available as
+  // Astro.locals.site.params.
+  PARAMS: {},
+
+  // Extra plugins: paths relative to the project root or package names.
+  // See src/utils/plugins.js for what a plugin module can export.
+  PLUGINS: [],
+
+  // Write the data behind a post's page to /blog/<post>/data.json, for
+  // working on layouts: true for every post, or a list of post ids.
+  TEMPLATE_DEBUG: false,
+
+  // Leave posts dated in the future out of the build until a build after
+  // their date. The next one's date is printed, and written as an ISO
+  // timestamp to REBUILD_HINT_FILE (e.g. '.next-build') for CI to schedule
+  // that build; the file is removed while nothing is waiting.
+  // true to enable, false to disable
+  SCHEDULED_POSTS: false,
+  REBUILD_HINT_FILE: '',
+
   // Debug mode for metadata generation.
   // true to enable, false to disable
   DEBUG: false,
+
+  // Write build debug output to this file instead of the console, e.g.
+  // 'build.log'. Warnings still show on the console too. The file is started
+  // over once per build and holds the site's own plugins and utilities;
+  // Astro's and the integrations' messages stay on the console. Leave empty
+  // to disable.
+  LOG_FILE: '',
 };
 
 export default siteConfig;
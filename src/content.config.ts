@@ -1,23 +1,141 @@
 import { defineCollection, z } from 'astro:content';
-import { glob } from 'astro/loaders';
+import { file, glob, type Loader } from 'astro/loaders';
+import { readFileSync } from 'fs';
+import { dirname } from 'path';
+import { withCascade } from './utils/cascade';
+import { withContentAdapters } from './utils/contentAdapters';
+import { withFileNameDate } from './utils/fileNameDate';
+import { normalizeMetadataKeys, readCommentMetadata, withCommentMetadata } from './utils/commentMetadata';
+import { getContentPatterns, getEntryId, splitContentPath, toEntryId, toOutputPath } from './utils/contentPaths';
+import { loadPlugins } from './utils/plugins.js';
+import { withRootPriority } from './utils/rootPriority';
+import { withSchedule } from './utils/schedule';
+import siteConfig from '../site.config.mjs';
+
+// A list of strings, given either as a proper YAML list or as one
+// comma-separated string (the form comment metadata uses).
+const stringList = () => z.preprocess(
+  (value) => typeof value === 'string'
+    ? value.split(',').map((item) => item.trim()).filter(Boolean)
+    : value,
+  z.array(z.string()),
+);
+
+const plugins = await loadPlugins(siteConfig.PLUGINS);
+
+// With SCHEDULED_POSTS, future-dated posts wait for a build after their date.
+const scheduled = (loader: Loader) => siteConfig.SCHEDULED_POSTS
+  ? withSchedule(loader, { hintFile: siteConfig.REBUILD_HINT_FILE })
+  : loader;
+
+// Strict, so a misspelt key (`ttitle:`) fails the build instead of
+// silently producing an untitled post.
+const blogMetadata = z.object({
+  slug: z.string().optional(),
+  author: z.string().default(siteConfig.DEFAULT_AUTHOR),
+  // Posts written by more than one person list them all here; when set it
+  // replaces `author`.
+  authors: stringList().default([]),
+  tags: stringList().default([]),
+  // Coarse grouping next to the free-form tags. Defaults to the top-level
+  // directory the post is in.
+  category: z.string().optional(),
+  // Multi-part posts: the series name, and this post's place in it.
+  // Parts without a number follow the numbered ones by date.
+  series: z.string().optional(),
+  seriesPart: z.coerce.number().int().positive().optional(),
+  date: z.coerce.date().optional(),
+  // When the post was last meaningfully revised. Defaults to the last commit.
+  updated: z.coerce.date().optional(),
+  title: z.string().optional(),
+  description: z.string().optional(),
+  // Social preview image: a URL, or a path below public/. Without one a
+  // card is generated when OG_CARDS is on.
+  image: z.string().optional(),
+  commitHash: z.string().optional(),
+  commitDate: z.string().optional(),
+  commitAuthor: z.string().optional(),
+  readTime: z.string().optional(),
+  // URLs of copies of this post published elsewhere (dev.to, Medium, a
+  // Mastodon thread), linked from the post with rel="syndication".
+  syndication: stringList().pipe(z.array(z.string().url())).default([]),
+  // Old paths of this post, e.g. `/blog/old-name/`, that redirect to it.
+  aliases: stringList().default([]),
+  // Exact path to publish this post at instead of /blog/<post>/, e.g.
+  // `/2019/05/hello/` or `/old/hello.html` to keep a URL from an earlier
+  // site. Also spelled `OutputPath:`.
+  url: z.string().transform((value, ctx) => {
+    const path = toOutputPath(value);
+    if (!path) {
+      ctx.addIssue({ code: z.ZodIssueCode.custom, message: `"${value}" must be a path like /2019/05/hello/ or /old/hello.html, without . or .. segments` });
+      return z.NEVER;
+    }
+    return path;
+  }).optional(),
+  // Extra stylesheets loaded on this post only.
+  extraCSS: stringList().default([]),
+}).strict();
 
 const blog = defineCollection({
-  loader: glob({ pattern: '**/*.md', base: './src/content/blog' }),
+  loader: scheduled(withContentAdapters(withRootPriority(withCascade(withFileNameDate(withCommentMetadata(glob({
+    // Files starting with `_` (like section `_index.md` files) are not posts.
+    pattern: getContentPatterns('**/[!_]*.md'),
+    base: '.',
+    // The loader only hands over YAML frontmatter here, so pick up a `Slug:`
+    // from a comment metadata block ourselves.
+    generateId: ({ entry, data }) => getEntryId(
+      splitContentPath(entry)?.relativePath ?? entry,
+      { ...readCommentMetadata(readFileSync(entry, 'utf-8')), ...normalizeMetadataKeys(data) },
+    ),
+  })))), Object.keys(blogMetadata.shape)), plugins.contentAdapters)),
+  schema: blogMetadata.superRefine((data, ctx) => {
+    for (const key of siteConfig.REQUIRED_METADATA) {
+      if (data[key as keyof typeof data] === undefined) {
+        ctx.addIssue({ code: z.ZodIssueCode.custom, path: [key], message: `${key} is required by REQUIRED_METADATA` });
+      }
+    }
+  }),
+});
+
+// Optional `_index.md` in a blog directory configures that directory's index page.
+const sections = defineCollection({
+  loader: withCommentMetadata(glob({
+    pattern: getContentPatterns('**/_index.md'),
+    base: '.',
+    generateId: ({ entry }) => {
+      const directory = dirname(splitContentPath(entry)?.relativePath ?? entry);
+      // The content root's own `_index.md` configures the blog index.
+      return directory === '.' ? '' : toEntryId(directory);
+    },
+  })),
   schema: z.object({
-    author: z.string().default('Kreato'),
-    tags: z.array(z.string()).default([]),
-    date: z.coerce.date().optional(),
     title: z.string().optional(),
+    // Short summary shown next to the directory in the parent listing. Falls
+    // back to the first paragraph of the file's body.
+    description: z.string().optional(),
+    // Social preview image for the directory's index page: a URL, or a path
+    // below public/ like `/images/nim.png`.
+    image: z.string().optional(),
+    // Set to false to skip generating an index page for this directory.
+    index: z.boolean().default(true),
+    // Metadata inherited by every post below this directory, unless the
+    // post (or a nearer section) sets it.
+    cascade: z.record(z.string(), z.unknown()).optional(),
+  }),
+});
+
+// Display names, descriptions and ordering for tags, keyed by the tag.
+const tags = defineCollection({
+  loader: file('src/content/tags.yaml'),
+  schema: z.object({
+    name: z.string().optional(),
     description: z.string().optional(),
-    commitHash: z.string().optional(),
-    commitDate: z.string().optional(),
-    commitAuthor: z.string().optional(),
-    readTime: z.string().optional(),
+    order: z.number().optional(),
   }),
 });
 
 const landing = defineCollection({
-  loader: glob({ pattern: '**/*.md', base: './src/content/landing' }),
+  loader: withCommentMetadata(glob({ pattern: '**/*.md', base: './src/content/landing' })),
   schema: z.object({
     title: z.string(),
     description: z.string(),
@@ -31,4 +149,4 @@ const landing = defineCollection({
   }),
 });
 
-export const collections = { blog, landing };
+export const collections = { blog, sections, tags, landing };
@@ -0,0 +1,114 @@
+import { spawn } from 'child_process';
+import { readdirSync } from 'fs';
+import type { IncomingMessage } from 'http';
+import { join } from 'path';
+import { fileURLToPath } from 'url';
+import type { AstroIntegration } from 'astro';
+
+export const BUILD_ENDPOINT = '/__krea/build';
+
+interface BuildReport {
+  ok: boolean;
+  exitCode: number | null;
+  durationMs: number;
+  pages: number;
+  output: string;
+}
+
+function countPages(dir: string): number {
+  try {
+    return readdirSync(dir, { recursive: true })
+      .filter(file => String(file).endsWith('.html'))
+      .length;
+  } catch {
+    return 0;
+  }
+}
+
+// Browsers send these on every POST, so a page on another site can't start
+// a build through a form or fetch. Clients outside a browser send neither.
+function isCrossSite(req: IncomingMessage): boolean {
+  const site = req.headers['sec-fetch-site'];
+  if (site && site !== 'same-origin' && site !== 'none') return true;
+
+  const origin = req.headers.origin;
+  if (!origin) return false;
+  try {
+    return new URL(origin).host !== req.headers.host;
+  } catch {
+    return true;
+  }
+}
+
+function runBuild(root: string, outDir: string): Promise<BuildReport> {
+  const started = Date.now();
+  const astro = join(root, 'node_modules/astro/astro.js');
+
+  return new Promise(resolve => {
+    const child = spawn(process.execPath, [astro, 'build'], { cwd: root, env: { ...process.env, FORCE_COLOR: '0' } });
+    let output = '';
+    child.stdout.on('data', chunk => { output += chunk; });
+    child.stderr.on('data', chunk => { output += chunk; });
+
+    child.on('close', exitCode => resolve({
+      ok: exitCode === 0,
+      exitCode,
+      durationMs: Date.now() - started,
+      pages: countPages(outDir),
+      // Keep the response small; the tail has the summary and any error.
+      output: output.split('\n').slice(-50).join('\n'),
+    }));
+  });
+}
+
+// Adds `POST /__krea/build` to the dev server. It runs a production build in
+// a child process and answers with a JSON report, so editor plugins and
+// external watchers can trigger builds without watching files themselves.
+export function devBuildAPI(): AstroIntegration {
+  let building = false;
+  let root = process.cwd();
+  let outDir = join(root, 'dist');
+
+  return {
+    name: 'dev-build-api',
+    hooks: {
+      'astro:config:done': ({ config }) => {
+        root = fileURLToPath(config.root);
+        outDir = fileURLToPath(config.outDir);
+      },
+      'astro:server:setup': ({ server, logger }) => {
+
+        server.middlewares.use(BUILD_ENDPOINT, async (req, res) => {
+          res.setHeader('Content-Type', 'application/json');
+
+          if (req.method !== 'POST') {
+            res.statusCode = 405;
+            res.end(JSON.stringify({ error: 'use POST' }));
+            return;
+          }
+          if (isCrossSite(req)) {
+            res.statusCode = 403;
+            res.end(JSON.stringify({ error: 'cross-site requests are not allowed' }));
+            return;
+          }
+          if (building) {
+            res.statusCode = 409;
+            res.end(JSON.stringify({ error: 'a build is already running' }));
+            return;
+          }
+
+          building = true;
+          logger.info('build requested');
+          try {
+            const report = await runBuild(root, outDir);
+            logger.info(`build ${report.ok ? 'finished' : 'failed'} in ${report.durationMs}ms`);
+            res.statusCode = report.ok ? 200 : 500;
+            res.end(JSON.stringify(report));
+          } finally {
+            building = false;
+          }
+        });
+      },
+    },
+  };
+}
@@ -0,0 +1,34 @@
+import { describe, expect, test } from 'bun:test';
+import { mkdirSync, mkdtempSync, symlinkSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { getCopyPath, getImagePath } from './imageOptimizer';
+
+const root = mkdtempSync(join(tmpdir(), 'krea-images-'));
+const output = join(root, 'dist');
+mkdirSync(join(output, 'blog'), { recursive: true });
+mkdirSync(join(root, 'photos'));
+symlinkSync(join(root, 'photos'), join(output, 'blog', 'photos'));
+
+describe('getImagePath', () => {
+  test.each([
+    ['/blog/post/photo.png', join(output, 'blog/post/photo.png')],
+    ['/_astro/photo.abc123.png', join(output, '_astro/photo.abc123.png')],
+    ['/blog/../photo.png', join(output, 'photo.png')],
+    ['/blog/../../photo.png', undefined],
+    ['/../../../etc/shadow.png', undefined],
+    ['/blog/photos/photo.png', undefined],
+  ])('%p → %p', (src, expected) => {
+    expect(getImagePath(output, src)).toBe(expected);
+  });
+});
+
+describe('getCopyPath', () => {
+  test.each([
+    ['/blog/post/photo.png', 'webp', '/blog/post/photo.png.webp'],
+    ['/blog/post/photo.jpg', 'webp', '/blog/post/photo.jpg.webp'],
+    ['/blog/post/photo.jpg', 'avif', '/blog/post/photo.jpg.avif'],
+  ])('%p as %p → %p', (src, format, expected) => {
+    expect(getCopyPath(src, format)).toBe(expected);
+  });
+});
@@ -0,0 +1,176 @@
+import { createHash } from 'crypto';
+import { readFileSync, readdirSync, writeFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { extname, join } from 'path';
+import type { AstroIntegration } from 'astro';
+import { isInside } from '../utils/outputPaths';
+import { writeFileAtomic } from '../utils/writeFileAtomic.js';
+import { BLOG_PATH, withBase, withoutBase } from '../utils/urls.js';
+
+const CACHE_DIR = join(process.cwd(), 'node_modules/.cache/krea.to/images');
+
+type Format = 'jpeg' | 'png' | 'webp' | 'avif';
+
+const FORMATS: Record<string, Format> = {
+  '.jpg': 'jpeg',
+  '.jpeg': 'jpeg',
+  '.png': 'png',
+  '.webp': 'webp',
+  '.avif': 'avif',
+};
+
+// Only these get modern copies; WebP and AVIF originals are served as they are.
+const CONVERTIBLE = new Set<Format>(['jpeg', 'png']);
+
+// Best compressed first, the order browsers should try the <source>s in.
+const MODERN_FORMATS: Array<'avif' | 'webp'> = ['avif', 'webp'];
+
+const ARTICLE = /<meta property="og:type" content="article">/;
+
+const IMG_TAG = /<img[^>]*\ssrc="(\/[^"?#]+)"[^>]*>/g;
+
+interface ImageOptimizerOptions {
+  // 0 keeps the original size.
+  maxWidth: number;
+  quality: number;
+  // Extra formats to offer through <picture>, e.g. ['webp', 'avif'].
+  formats: Array<'webp' | 'avif'>;
+}
+
+function getFormat(src: string): Format | undefined {
+  return FORMATS[extname(src).toLowerCase()];
+}
+
+// Blog pages, and posts pinned with `url` to a path outside the blog, which
+// are told apart from other pages by their article Open Graph type.
+function listPages(root: string): string[] {
+  return readdirSync(root, { recursive: true })
+    .map(String)
+    .filter(file => file.endsWith('.html'))
+    .map(file => join(root, file))
+    .filter(page => isInside(join(root, BLOG_PATH), page) || ARTICLE.test(readFileSync(page, 'utf-8')));
+}
+
+// Local images used by <img> tags on blog pages, as paths inside the output.
+function findPostImages(pages: string[]): Set<string> {
+  const images = new Set<string>();
+  for (const page of pages) {
+    for (const [, src] of readFileSync(page, 'utf-8').matchAll(IMG_TAG)) {
+      if (getFormat(src)) images.add(withoutBase(decodeURIComponent(src)));
+    }
+  }
+  return images;
+}
+
+// Where an image path from findPostImages is in the output, or undefined
+// for one like /blog/../../photo.png that names a file outside it.
+export function getImagePath(root: string, src: string): string | undefined {
+  const path = join(root, src);
+  return isInside(root, path) ? path : undefined;
+}
+
+// Where the `format` copy of an image goes. The original extension stays in
+// the name, so photo.png and photo.jpg don't share a photo.webp and a
+// photo.webp the post uses itself isn't overwritten.
+export function getCopyPath(src: string, format: string): string {
+  return `${src}.${format}`;
+}
+
+// After a build, shrink the images posts use to at most `maxWidth` pixels
+// wide and re-encode them at `quality`, in place. JPEG and PNG images also
+// get a copy in each of `formats` next to them, offered to browsers through
+// a <picture> element. Results are cached by content, so unchanged images
+// cost a hash per build.
+export function imageOptimizer({ maxWidth, quality, formats }: ImageOptimizerOptions): AstroIntegration {
+  return {
+    name: 'image-optimizer',
+    hooks: {
+      'astro:build:done': async ({ dir, logger }) => {
+        let sharp;
+        try {
+          sharp = (await import('sharp')).default;
+        } catch {
+          logger.warn('sharp is not installed, skipping image optimization');
+          return;
+        }
+
+        // Encode `input` as `format`, or return undefined when the result
+        // isn't smaller than `input` (already well compressed originals).
+        const encode = async (input: Buffer, format: Format): Promise<Buffer | undefined> => {
+          const hash = createHash('sha256').update(`${maxWidth}:${quality}:${format}:`).update(input).digest('hex');
+          const cachePath = join(CACHE_DIR, `${hash}.${format}`);
+          let output: Buffer;
+          try {
+            output = readFileSync(cachePath);
+          } catch {
+            let image = sharp(input).rotate();
+            if (maxWidth > 0) image = image.resize({ width: maxWidth, withoutEnlargement: true });
+            output = await image.toFormat(format, { quality }).toBuffer();
+            writeFileAtomic(cachePath, output);
+          }
+          return output.length < input.length ? output : undefined;
+        };
+
+        const root = fileURLToPath(dir);
+        const pages = listPages(root);
+        const modernFormats = MODERN_FORMATS.filter(format => formats.includes(format));
+        // Image path → the modern copies written for it, best first.
+        const alternatives = new Map<string, Array<{ src: string; format: string }>>();
+        let saved = 0;
+
+        for (const src of findPostImages(pages)) {
+          const path = getImagePath(root, src);
+          if (!path) {
+            logger.warn(`skipping ${src}, which is outside the build output`);
+            continue;
+          }
+          const format = getFormat(src)!;
+          let input: Buffer;
+          try {
+            input = readFileSync(path);
+          } catch {
+            continue;
+          }
+
+          if (maxWidth > 0) {
+            const output = await encode(input, format);
+            if (output) {
+              writeFileAtomic(path, output);
+              saved += input.length - output.length;
+              input = output;
+            }
+          }
+
+          if (!CONVERTIBLE.has(format)) continue;
+          for (const modern of modernFormats) {
+            const output = await encode(input, modern);
+            if (!output) continue;
+            const copy = getCopyPath(src, modern);
+            writeFileAtomic(join(root, copy), output);
+            if (!alternatives.has(src)) alternatives.set(src, []);
+            alternatives.get(src)!.push({ src: copy, format: modern });
+          }
+        }
+
+        if (alternatives.size > 0) {
+          for (const page of pages) {
+            const html = readFileSync(page, 'utf-8');
+            // Images already inside a <picture> made their own choice.
+            const rewritten = html.replace(/<picture[\s\S]*?<\/picture>|<img[^>]*>/g, (tag) => {
+              if (tag.startsWith('<picture')) return tag;
+              const src = tag.match(/\ssrc="(\/[^"?#]+)"/)?.[1];
+              const copies = src && alternatives.get(withoutBase(decodeURIComponent(src)));
+              if (!copies) return tag;
+              const sources = copies.map(copy => `<source srcset="${encodeURI(withBase(copy.src))}" type="image/${copy.format}">`).join('');
+              return `<picture>${sources}${tag}</picture>`;
+            });
+            if (rewritten !== html) writeFileSync(page, rewritten);
+          }
+        }
+
+        if (saved > 0) logger.info(`saved ${(saved / 1024 / 1024).toFixed(1)} MB on post images`);
+        if (alternatives.size > 0) logger.info(`wrote ${modernFormats.join('/')} copies of ${alternatives.size} post images`);
+      },
+    },
+  };
+}
@@ -0,0 +1,82 @@
+import { readFileSync } from 'fs';
+import type { AstroIntegration } from 'astro';
+import siteConfig from '../../site.config.mjs';
+import { hasSyndicationState, readSyndicationState, writeSyndicationState } from '../utils/syndicationState';
+import { OFFLINE, request } from '../utils/http.js';
+import { BLOG_PATH } from '../utils/urls.js';
+
+interface IndexedPost {
+  id: string;
+  title: string;
+  url: string;
+  tags: string[];
+}
+
+function formatStatus(post: IndexedPost): string {
+  const url = new URL(post.url, siteConfig.SITE_URL).href;
+  const hashtags = post.tags.map(tag => `#${tag.replace(/[^\p{L}\p{N}_]/gu, '')}`).filter(tag => tag.length > 1);
+  return [post.title, url, hashtags.join(' ')].filter(Boolean).join('\n\n');
+}
+
+async function postStatus(post: IndexedPost, token: string): Promise<string> {
+  const response = await request(`https://${siteConfig.MASTODON_POST_INSTANCE}/api/v1/statuses`, {
+    method: 'POST',
+    headers: {
+      Authorization: `Bearer ${token}`,
+      'Content-Type': 'application/json',
+      // Lets Mastodon drop the duplicate if a build is retried mid-request.
+      'Idempotency-Key': post.id,
+    },
+    body: JSON.stringify({ status: formatStatus(post), visibility: 'public' }),
+  }, { retries: 3 });
+  if (!response.ok) throw new Error(`Mastodon returned ${response.status}`);
+
+  const status = await response.json();
+  return status.url;
+}
+
+// After a build, announce posts that haven't been seen before on the
+// configured Mastodon account and record the status URLs in the syndication
+// state file, so the next build links them with rel="syndication".
+export function mastodonSyndication(): AstroIntegration {
+  return {
+    name: 'mastodon-syndication',
+    hooks: {
+      'astro:build:done': async ({ dir, logger }) => {
+        if (OFFLINE) {
+          logger.info('offline, skipping syndication');
+          return;
+        }
+
+        const token = process.env.MASTODON_TOKEN;
+        if (!token) {
+          logger.warn('MASTODON_TOKEN is not set, skipping syndication');
+          return;
+        }
+
+        const posts: IndexedPost[] = JSON.parse(readFileSync(new URL(`${BLOG_PATH}/search-index.json`, dir), 'utf-8'));
+        const state = readSyndicationState();
+
+        // The first run only records what already exists; announcing the
+        // whole back catalogue at once is never what anyone wants.
+        if (!hasSyndicationState()) {
+          for (const post of posts) state[post.id] = [];
+          writeSyndicationState(state);
+          logger.info(`recorded ${posts.length} existing posts, new posts will be syndicated from now on`);
+          return;
+        }
+
+        for (const post of posts.filter(post => !(post.id in state))) {
+          try {
+            state[post.id] = [await postStatus(post, token)];
+            logger.info(`syndicated ${post.id} to ${state[post.id][0]}`);
+          } catch (error) {
+            logger.warn(`could not syndicate ${post.id}: ${(error as Error).message}`);
+          }
+        }
+
+        writeSyndicationState(state);
+      },
+    },
+  };
+}
@@ -0,0 +1,36 @@
+import { createHash } from 'crypto';
+import { readFileSync, readdirSync, statSync, writeFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { join } from 'path';
+import type { AstroIntegration } from 'astro';
+import { joinInside } from '../utils/outputPaths';
+
+// Lists every built file with its size and SHA-256, so deploy tooling can
+// verify an upload is complete and unmodified. Must come after every
+// integration that writes to the output.
+export function outputManifest(fileName: string): AstroIntegration {
+  return {
+    name: 'output-manifest',
+    hooks: {
+      'astro:build:done': ({ dir, logger }) => {
+        const root = fileURLToPath(dir);
+        const files: Record<string, { size: number; sha256: string }> = {};
+
+        const paths = readdirSync(root, { recursive: true })
+          .map(String)
+          .filter(path => path !== fileName && statSync(join(root, path)).isFile())
+          .sort();
+        for (const path of paths) {
+          const contents = readFileSync(join(root, path));
+          files[path.split('\\').join('/')] = {
+            size: contents.length,
+            sha256: createHash('sha256').update(contents).digest('hex'),
+          };
+        }
+
+        writeFileSync(joinInside(root, fileName), `${JSON.stringify({ files }, null, 2)}\n`);
+        logger.info(`listed ${paths.length} files in ${fileName}`);
+      },
+    },
+  };
+}
@@ -0,0 +1,34 @@
+import { readFileSync, readdirSync, rmSync, writeFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { dirname, join, sep } from 'path';
+import type { AstroIntegration } from 'astro';
+
+// Astro writes every page as <path>/index.html, including posts whose `url`
+// pins them to a file like /old/hello.html. This moves those pages to the
+// file itself. Must come before integrations that read the output.
+// Pages whose directory holds anything else (another page below the pinned
+// path) fail the build, as moving them would delete those files.
+export function pinnedFiles(): AstroIntegration {
+  return {
+    name: 'pinned-files',
+    hooks: {
+      'astro:build:done': ({ dir, logger }) => {
+        const root = fileURLToPath(dir);
+        const pages = readdirSync(root, { recursive: true })
+          .map(String)
+          .filter(path => path.endsWith(`.html${sep}index.html`));
+
+        for (const page of pages) {
+          const directory = join(root, dirname(page));
+          if (readdirSync(directory).length > 1) {
+            throw new Error(`cannot move ${page} to ${dirname(page)}, which holds other files too`);
+          }
+          const html = readFileSync(join(root, page));
+          rmSync(directory, { recursive: true });
+          writeFileSync(directory, html);
+        }
+        if (pages.length > 0) logger.info(`moved ${pages.length} pages to their pinned file names`);
+      },
+    },
+  };
+}
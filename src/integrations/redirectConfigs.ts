@@ -0,0 +1,56 @@
+import { writeFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { join } from 'path';
+import type { AstroIntegration } from 'astro';
+import { getRedirects, type Redirect } from '../utils/redirects';
+import { withBase } from '../utils/urls.js';
+
+// Redirect rules for hosts that read them from the build output. Unlike the
+// stub pages, these send real HTTP statuses and support `*` wildcards.
+const FORMATS: Record<string, { file: string; render: (redirects: Redirect[]) => string }> = {
+  // Netlify and Cloudflare Pages.
+  netlify: {
+    file: '_redirects',
+    render: redirects => redirects.map(({ from, to, status }) => `${from} ${to} ${status}\n`).join(''),
+  },
+  // An include file for an nginx `server` block. nginx's rewrite only
+  // knows permanent (301) and temporary (302) redirects.
+  nginx: {
+    file: 'redirects.nginx.conf',
+    render: redirects => redirects.map(({ from, to, status }) => {
+      const permanent = status === 301 || status === 308 ? 'permanent' : 'redirect';
+      if (!from.includes('*')) return `rewrite ^${escapeRegex(from)}$ ${to} ${permanent};\n`;
+      const pattern = from.split('*').map(escapeRegex).join('(.*)');
+      return `rewrite ^${pattern}$ ${to.replace(':splat', '$1')} ${permanent};\n`;
+    }).join(''),
+  },
+};
+
+function escapeRegex(text: string): string {
+  return text.replace(/[.+?^${}()|[\]\\]/g, '\\$&');
+}
+
+export function redirectConfigs(formats: string[]): AstroIntegration {
+  for (const format of formats) {
+    if (!FORMATS[format]) {
+      throw new Error(`Unknown redirect config format "${format}" (available: ${Object.keys(FORMATS).join(', ')})`);
+    }
+  }
+
+  return {
+    name: 'redirect-configs',
+    hooks: {
+      'astro:build:done': ({ dir, logger }) => {
+        const redirects = getRedirects().map(redirect => ({ ...redirect, from: withBase(redirect.from), to: withBase(redirect.to) }));
+        if (redirects.length === 0) return;
+
+        const root = fileURLToPath(dir);
+        for (const format of formats) {
+          const { file, render } = FORMATS[format];
+          writeFileSync(join(root, file), render(redirects));
+          logger.info(`wrote ${redirects.length} redirects to ${file}`);
+        }
+      },
+    },
+  };
+}
@@ -0,0 +1,44 @@
+import { readdirSync, readFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { join } from 'path';
+import type { AstroIntegration } from 'astro';
+
+// What every full page must carry in its <head>. Layouts that forget one of
+// these still build fine but break subtly (mojibake, desktop-width pages on
+// phones), so the build checks the output.
+const REQUIREMENTS = [
+  { name: '<html lang>', pattern: /<html[^>]*\slang="[^"]+"/i },
+  { name: '<meta charset>', pattern: /<meta[^>]*\scharset=/i },
+  { name: '<meta name="viewport">', pattern: /<meta[^>]*\sname="viewport"/i },
+  { name: '<title>', pattern: /<title>[^<]+<\/title>/i },
+];
+
+const FULL_PAGE = /^\s*<!doctype html/i;
+
+export function templateContract({ strict = false }: { strict?: boolean } = {}): AstroIntegration {
+  return {
+    name: 'template-contract',
+    hooks: {
+      'astro:build:done': ({ dir, logger }) => {
+        const root = fileURLToPath(dir);
+        const pages = readdirSync(root, { recursive: true })
+          .map(String)
+          .filter(file => file.endsWith('.html'));
+
+        const problems: string[] = [];
+        for (const page of pages) {
+          const html = readFileSync(join(root, page), 'utf-8');
+          // Only full pages, which includes posts pinned to a file like
+          // /old/hello.html; fragments and other .html endpoints have no head.
+          if (!FULL_PAGE.test(html)) continue;
+          const missing = REQUIREMENTS.filter(({ pattern }) => !pattern.test(html)).map(({ name }) => name);
+          if (missing.length > 0) problems.push(`${page}: missing ${missing.join(', ')}`);
+        }
+
+        if (problems.length === 0) return;
+        for (const problem of problems) logger.warn(problem);
+        if (strict) throw new Error(`${problems.length} pages are missing required head elements`);
+      },
+    },
+  };
+}
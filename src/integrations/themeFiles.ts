@@ -0,0 +1,85 @@
+import { copyFileSync, existsSync, mkdirSync, readFileSync, readdirSync, statSync } from 'fs';
+import { dirname, extname, join, normalize, relative } from 'path';
+import { fileURLToPath } from 'url';
+import type { AstroIntegration } from 'astro';
+
+const CONTENT_TYPES: Record<string, string> = {
+  '.css': 'text/css; charset=utf-8',
+  '.js': 'text/javascript; charset=utf-8',
+  '.svg': 'image/svg+xml',
+  '.png': 'image/png',
+  '.jpg': 'image/jpeg',
+  '.jpeg': 'image/jpeg',
+  '.webp': 'image/webp',
+  '.gif': 'image/gif',
+  '.woff2': 'font/woff2',
+  '.woff': 'font/woff',
+  '.ttf': 'font/ttf',
+  '.ico': 'image/x-icon',
+};
+
+// What public/ holds at its top level (css/, js/, favicon.ico, ...). A theme
+// directory is often a repository of its own, so only files below these
+// are published from it, never its .git, README or package.json.
+const PUBLIC_ENTRIES = new Set(readdirSync(join(process.cwd(), 'public')));
+
+// Whether `path`, relative to a theme directory, is one to publish.
+function isPublicPath(path: string): boolean {
+  const segments = path.split(/[\\/]/).filter(Boolean);
+  return PUBLIC_ENTRIES.has(segments[0]) && segments.every(segment => !segment.startsWith('.'));
+}
+
+// Publishes THEME_DIRS on top of public/: the dev server answers with their
+// files first, and a build copies them over the output, the first directory
+// last so it wins.
+export function themeFiles(dirs: string[]): AstroIntegration {
+  return {
+    name: 'theme-files',
+    hooks: {
+      'astro:server:setup': ({ server }) => {
+        const base = server.config.base.replace(/\/$/, '');
+
+        server.middlewares.use((req, res, next) => {
+          let pathname: string;
+          try {
+            pathname = decodeURIComponent(new URL(req.url ?? '/', 'http://localhost').pathname);
+          } catch {
+            // Malformed escapes are for Astro to answer.
+            return next();
+          }
+          if (!pathname.startsWith(`${base}/`)) return next();
+          // An absolute path normalizes without any `..` left, so a request
+          // can't reach outside the directories.
+          const path = normalize(pathname.slice(base.length));
+          if (!isPublicPath(path)) return next();
+
+          const file = dirs
+            .map(dir => join(dir, path))
+            .find(file => existsSync(file) && statSync(file).isFile());
+          if (!file) return next();
+
+          res.setHeader('Content-Type', CONTENT_TYPES[extname(file)] ?? 'application/octet-stream');
+          res.end(readFileSync(file));
+        });
+      },
+      'astro:build:done': ({ dir, logger }) => {
+        const root = fileURLToPath(dir);
+        for (const themeDir of [...dirs].reverse()) {
+          if (!existsSync(themeDir)) {
+            logger.warn(`theme directory ${themeDir} does not exist`);
+            continue;
+          }
+          const files = readdirSync(themeDir, { recursive: true, withFileTypes: true })
+            .filter(entry => entry.isFile())
+            .map(entry => relative(themeDir, join(entry.parentPath, entry.name)))
+            .filter(isPublicPath);
+          for (const file of files) {
+            mkdirSync(dirname(join(root, file)), { recursive: true });
+            copyFileSync(join(themeDir, file), join(root, file));
+          }
+        }
+        if (dirs.length > 0) logger.info(`copied ${dirs.length} theme directories`);
+      },
+    },
+  };
+}
@@ -0,0 +1,34 @@
+import { describe, expect, test } from 'bun:test';
+import { mkdirSync, mkdtempSync, symlinkSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { leavesSite } from './urlPolicy';
+
+const root = mkdtempSync(join(tmpdir(), 'krea-links-'));
+const output = join(root, 'dist');
+mkdirSync(join(output, 'blog', 'post'), { recursive: true });
+mkdirSync(join(root, 'secret'));
+symlinkSync(join(root, 'secret'), join(output, 'blog', 'escape'));
+
+describe('leavesSite', () => {
+  test.each([
+    ['photo.png', false],
+    ['./photo.png', false],
+    ['../other/', false],
+    ['../../index.html', false],
+    ['/blog/other/', false],
+    ['/../../etc/passwd', false],
+    ['../../../photo.png', true],
+    ['../../../../etc/passwd', true],
+    ['%2e%2e/%2e%2e/%2e%2e/photo.png', true],
+    ['%2E%2E/%2E%2E/%2E%2E/', true],
+    ['..\\..\\..\\photo.png', true],
+    ['..%5c..%5c..%5cphoto.png', true],
+    // Through a symlink that points out of the output.
+    ['../escape/photo.png', true],
+    // Malformed escapes can't be resolved, so they are left to other checks.
+    ['%E0%A4%A/photo.png', false],
+  ])('%p from blog/post/index.html → %p', (reference, expected) => {
+    expect(leavesSite(output, 'blog/post/index.html', reference)).toBe(expected);
+  });
+});
@@ -0,0 +1,79 @@
+import { readdirSync, readFileSync } from 'fs';
+import { fileURLToPath } from 'url';
+import { dirname, join } from 'path';
+import type { AstroIntegration } from 'astro';
+import { isInside } from '../utils/outputPaths';
+import { URL_STYLE, pageURL } from '../utils/urls.js';
+
+const HREF = /\shref="([^"]*)"/g;
+// Links and sources without a scheme, up to any query or fragment.
+const LOCAL_REFERENCE = /\s(?:href|src)="(?![a-z][a-z0-9+.-]*:)([^"#?]+)/gi;
+
+// Whether a relative link on `page` climbs out of the site with `..`, like
+// an embed resolved to ../../../../photo.png. Browsers quietly stop at the
+// root, so it loads some other page or nothing. Browsers read `\` in a URL
+// as `/`, so it counts as one here too.
+export function leavesSite(root: string, page: string, reference: string): boolean {
+  let path: string;
+  try {
+    path = decodeURIComponent(reference).replace(/\\/g, '/');
+  } catch {
+    return false;
+  }
+  if (path.startsWith('/')) return false;
+  return !isInside(root, join(root, dirname(page), path));
+}
+
+// The same path spelled as URL_STYLE wants it, when `href` is an internal
+// page link spelled differently. Extensionless paths are directories
+// missing their slash.
+function getExpectedHref(href: string, origin: string): string | undefined {
+  const path = href.startsWith(origin + '/') ? href.slice(origin.length) : href;
+  if (!path.startsWith('/') || path.startsWith('//')) return undefined;
+
+  const [, pathname, suffix] = path.match(/^([^?#]*)(.*)$/)!;
+  const lastSegment = pathname.slice(pathname.lastIndexOf('/') + 1);
+  const directory = lastSegment.includes('.') ? pathname : `${pathname}/`;
+  const expected = pageURL(directory) + suffix;
+  return expected === path ? undefined : expected;
+}
+
+// After a build, flag internal links that don't follow URL_STYLE, such as
+// a hand-written `/blog/post` or `/blog/post/index.html` in a post. Both
+// load the page, but each spelling is a separate URL to search engines.
+// Relative links that climb out of the site fail the build.
+export function urlPolicy({ site }: { site: string }): AstroIntegration {
+  const origin = new URL(site).origin;
+  return {
+    name: 'url-policy',
+    hooks: {
+      'astro:build:done': ({ dir, logger }) => {
+        const root = fileURLToPath(dir);
+        const pages = readdirSync(root, { recursive: true })
+          .map(String)
+          .filter(file => file.endsWith('.html'));
+
+        let count = 0;
+        const escaping = new Set<string>();
+        for (const page of pages) {
+          const html = readFileSync(join(root, page), 'utf-8');
+          const links = new Set<string>();
+          for (const [, href] of html.matchAll(HREF)) {
+            const expected = getExpectedHref(href, origin);
+            if (expected) links.add(`${href} (use ${expected})`);
+          }
+          for (const link of links) logger.warn(`${page}: ${link}`);
+          count += links.size;
+
+          for (const [, reference] of html.matchAll(LOCAL_REFERENCE)) {
+            if (leavesSite(root, page, reference)) escaping.add(`${page}: ${reference}`);
+          }
+        }
+
+        if (count > 0) logger.warn(`${count} links don't follow URL_STYLE '${URL_STYLE}'`);
+        for (const link of escaping) logger.error(`${link} points outside the site`);
+        if (escaping.size > 0) throw new Error(`${escaping.size} links point outside the site`);
+      },
+    },
+  };
+}
@@ -0,0 +1,8 @@
+import { defineMiddleware } from 'astro:middleware';
+import { getSite } from './utils/site';
+
+// Hand every page the site-wide context as `Astro.locals.site`.
+export const onRequest = defineMiddleware(async (context, next) => {
+  context.locals.site = await getSite();
+  return next();
+});
@@ -0,0 +1,21 @@
+import { getCollection } from 'astro:content';
+import type { APIRoute } from 'astro';
+import { BLOG_PATH } from '../../utils/urls.js';
+import siteConfig from '../../../site.config.mjs';
+import { toDevtoMarkdown } from '../../utils/devto';
+
+export async function getStaticPaths() {
+  if (!siteConfig.DEVTO_EXPORT) return [];
+
+  const posts = await getCollection('blog');
+  return posts.map(post => ({
+    params: { blog: BLOG_PATH, slug: post.id },
+    props: { post },
+  }));
+}
+
+export const GET: APIRoute = ({ props }) => {
+  return new Response(toDevtoMarkdown(props.post), {
+    headers: { 'Content-Type': 'text/markdown; charset=utf-8' },
+  });
+};
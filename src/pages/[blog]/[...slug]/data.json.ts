@@ -0,0 +1,42 @@
+import { getCollection } from 'astro:content';
+import type { APIRoute } from 'astro';
+import { BLOG_PATH } from '../../../utils/urls.js';
+import siteConfig from '../../../../site.config.mjs';
+import { getPostDate, getPostTitle, getPostURL, getRelatedPosts } from '../../../utils/content';
+import { getPostComputedMetadataById } from '../../../utils/postMetadata';
+
+export async function getStaticPaths() {
+  const debug = siteConfig.TEMPLATE_DEBUG;
+  if (!debug || (Array.isArray(debug) && debug.length === 0)) return [];
+
+  const posts = await getCollection('blog');
+  return posts
+    .filter(post => debug === true || debug.includes(post.id))
+    .map(post => ({
+      params: { blog: BLOG_PATH, slug: post.id },
+      props: { post },
+    }));
+}
+
+// Everything the post layout works from, for people writing layouts: the
+// entry's validated metadata, what the git layer computed, and what remark
+// plugins added while rendering.
+export const GET: APIRoute = async ({ props }) => {
+  const { post } = props;
+  const debugData = {
+    id: post.id,
+    url: getPostURL(post),
+    title: getPostTitle(post),
+    date: getPostDate(post),
+    data: post.data,
+    computed: getPostComputedMetadataById(post.id),
+    filePath: post.filePath,
+    remarkPluginFrontmatter: post.rendered?.metadata?.frontmatter,
+    headings: post.rendered?.metadata?.headings,
+    relatedPosts: (await getRelatedPosts(post, siteConfig.RELATED_POSTS, siteConfig.RELATED_POSTS_MIN_SHARED_TAGS)).map(related => related.id),
+  };
+
+  return new Response(JSON.stringify(debugData, null, 2), {
+    headers: { 'Content-Type': 'application/json' },
+  });
+};
@@ -0,0 +1,24 @@
+import { getCollection } from 'astro:content';
+import type { APIRoute } from 'astro';
+import { renderPostBody, withAbsoluteURLs } from '../../../utils/postHTML';
+import { BLOG_PATH } from '../../../utils/urls.js';
+import siteConfig from '../../../../site.config.mjs';
+
+export async function getStaticPaths() {
+  if (!siteConfig.FRAGMENTS) return [];
+
+  const posts = await getCollection('blog');
+  return posts.map(post => ({
+    params: { blog: BLOG_PATH, slug: post.id },
+    props: { post },
+  }));
+}
+
+// The rendered article body alone, without any layout, for embedding posts
+// into another site that provides its own chrome. Its URLs are absolute, as
+// they are resolved against the embedding page.
+export const GET: APIRoute = async ({ props }) => {
+  return new Response(withAbsoluteURLs(await renderPostBody(props.post)), {
+    headers: { 'Content-Type': 'text/html; charset=utf-8' },
+  });
+};
@@ -0,0 +1,27 @@
+import { getCollection } from 'astro:content';
+import type { APIRoute } from 'astro';
+import { getPostDate, getPostTitle } from '../../../utils/content';
+import { renderSocialCard } from '../../../utils/socialCard';
+import { BLOG_PATH } from '../../../utils/urls.js';
+import siteConfig from '../../../../site.config.mjs';
+
+export async function getStaticPaths() {
+  if (!siteConfig.OG_CARDS) return [];
+
+  // Posts with their own image don't need a generated one.
+  const posts = await getCollection('blog', post => !post.data.image);
+  return posts.map(post => ({
+    params: { blog: BLOG_PATH, slug: post.id },
+    props: { post },
+  }));
+}
+
+export const GET: APIRoute = async ({ props }) => {
+  const card = await renderSocialCard(getPostTitle(props.post), getPostDate(props.post));
+  if (!card) {
+    throw new Error('OG_CARDS needs the sharp package to render social cards');
+  }
+  return new Response(card, {
+    headers: { 'Content-Type': 'image/png' },
+  });
+};
@@ -0,0 +1,38 @@
+import rss from '@astrojs/rss';
+import { getCategories, getPostAuthors, getPostDate, getPostSummary, getPostTitle, getPostURL, hasCategoryIndex } from '../../../utils/content';
+import { getChannelCustomData, getItemContent } from '../../../utils/feed';
+import { hasTaxonomyFeed } from '../../../utils/taxonomies';
+import { BLOG_PATH } from '../../../utils/urls.js';
+import siteConfig from '../../../../site.config.mjs';
+
+export async function getStaticPaths() {
+  if (!hasTaxonomyFeed('categories')) return [];
+
+  const categories = await getCategories();
+
+  return categories.filter(hasCategoryIndex).map(category => ({
+    params: { blog: BLOG_PATH, category: category.slug },
+    props: { category },
+  }));
+}
+
+// Posts in one category, for readers who only follow one part of the blog.
+export async function GET(context) {
+  const { category } = context.props;
+  const posts = category.posts.slice(0, siteConfig.FEED_LIMIT || undefined);
+
+  return rss({
+    title: `${siteConfig.TITLE}: ${category.name}`,
+    description: category.description ?? `Posts in ${category.name}: ${siteConfig.FEED_DESCRIPTION}`,
+    site: context.site,
+    customData: getChannelCustomData(),
+    items: await Promise.all(posts.map(async post => ({
+      title: getPostTitle(post),
+      pubDate: getPostDate(post),
+      description: getPostSummary(post),
+      content: await getItemContent(post),
+      link: getPostURL(post),
+      author: getPostAuthors(post).join(', '),
+    }))),
+  });
+}
@@ -0,0 +1,40 @@
+import rss from '@astrojs/rss';
+import { getPostAuthors, getPostDate, getPostSummary, getPostTitle, getPostURL, sortPostsByDate } from '../../../../utils/content';
+import { getChannelCustomData, getItemContent } from '../../../../utils/feed';
+import { TAXONOMIES, getTerms, hasTaxonomyFeed } from '../../../../utils/taxonomies';
+import { BLOG_PATH } from '../../../../utils/urls.js';
+import siteConfig from '../../../../../site.config.mjs';
+
+export async function getStaticPaths() {
+  const taxonomies = Object.entries(TAXONOMIES)
+    .filter(([taxonomy, definition]) => definition.pages !== false && hasTaxonomyFeed(taxonomy));
+  const paths = await Promise.all(taxonomies.map(async ([taxonomy, definition]) =>
+    (await getTerms(taxonomy)).map(term => ({
+      params: { blog: BLOG_PATH, taxonomy: definition.path, term: term.slug },
+      props: { term },
+    }))));
+  return paths.flat();
+}
+
+// Posts with one term, for readers who only follow a single topic or author.
+export async function GET(context) {
+  const { term } = context.props;
+  const definition = TAXONOMIES[term.taxonomy];
+  const posts = sortPostsByDate([...term.posts])
+    .slice(0, siteConfig.FEED_LIMIT || undefined);
+
+  return rss({
+    title: `${siteConfig.TITLE}: ${term.name}`,
+    description: term.description ?? `${definition.heading.replace(/:$/, '')} ${term.name}: ${siteConfig.FEED_DESCRIPTION}`,
+    site: context.site,
+    customData: getChannelCustomData(),
+    items: await Promise.all(posts.map(async post => ({
+      title: getPostTitle(post),
+      pubDate: getPostDate(post),
+      description: getPostSummary(post),
+      content: await getItemContent(post),
+      link: getPostURL(post),
+      author: getPostAuthors(post).join(', '),
+    }))),
+  });
+}
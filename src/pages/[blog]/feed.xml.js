@@ -0,0 +1,30 @@
+import rss from '@astrojs/rss';
+import { getCollection } from 'astro:content';
+import { getPostAuthors, getPostDate, getPostSummary, getPostTitle, getPostURL, sortPostsByDate } from '../../utils/content';
+import { getChannelCustomData, getItemContent } from '../../utils/feed';
+import { BLOG_PATH } from '../../utils/urls.js';
+import siteConfig from '../../../site.config.mjs';
+
+export function getStaticPaths() {
+  return [{ params: { blog: BLOG_PATH } }];
+}
+
+export async function GET(context) {
+  const posts = sortPostsByDate(await getCollection('blog'))
+    .slice(0, siteConfig.FEED_LIMIT || undefined);
+  
+  return rss({
+    title: siteConfig.TITLE,
+    description: siteConfig.FEED_DESCRIPTION,
+    site: context.site,
+    customData: getChannelCustomData(),
+    items: await Promise.all(posts.map(async post => ({
+      title: getPostTitle(post),
+      pubDate: getPostDate(post),
+      description: getPostSummary(post),
+      content: await getItemContent(post),
+      link: getPostURL(post),
+      author: getPostAuthors(post).join(', '),
+    }))),
+  });
+}
@@ -1,6 +1,12 @@
 import { getCollection } from 'astro:content';
-import { getPostTitle } from '../../utils/content';
+import { getPostSummary, getPostTitle, getPostURL } from '../../utils/content';
+import { getPostComputedMetadataById } from '../../utils/postMetadata';
 import { marked } from 'marked';
+import { BLOG_PATH } from '../../utils/urls.js';
+
+export function getStaticPaths() {
+  return [{ params: { blog: BLOG_PATH } }];
+}
 
 function stripHtml(html) {
   return html.replace(/<[^>]*>/g, ' ').replace(/\s+/g, ' ').trim();
@@ -19,12 +25,13 @@ export async function GET() {
     
     return {
       title: getPostTitle(post),
-      description: post.data.description || '',
-      slug: post.id.replace(/\.md$/, ''),
-      url: `/blog/${post.id.replace(/\.md$/, '')}/`,
+      description: getPostSummary(post) || '',
+      slug: post.id,
+      url: getPostURL(post),
       id: post.id,
       tags: post.data.tags || [],
       date: post.data.date?.toISOString() || '',
+      lastModified: getPostComputedMetadataById(post.id)?.lastModified || '',
       content
     };
   });
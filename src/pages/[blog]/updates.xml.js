@@ -0,0 +1,37 @@
+import rss from '@astrojs/rss';
+import { getCollection } from 'astro:content';
+import { getPostAuthors, getPostSummary, getPostTitle, getPostURL } from '../../utils/content';
+import { getPostComputedMetadataById } from '../../utils/postMetadata';
+import { getChannelCustomData } from '../../utils/feed';
+import { BLOG_PATH } from '../../utils/urls.js';
+import siteConfig from '../../../site.config.mjs';
+
+export function getStaticPaths() {
+  return [{ params: { blog: BLOG_PATH } }];
+}
+
+// Recently modified posts, for readers who want to follow edits rather than
+// only new posts. Ordered by last commit (or file mtime) instead of post date.
+export async function GET(context) {
+  const posts = (await getCollection('blog'))
+    .map(post => ({ post, computed: getPostComputedMetadataById(post.id) }))
+    .filter(({ computed }) => computed?.lastModified)
+    .sort((a, b) => new Date(b.computed.lastModified).valueOf() - new Date(a.computed.lastModified).valueOf())
+    .slice(0, siteConfig.UPDATES_FEED_LIMIT);
+
+  return rss({
+    title: `${siteConfig.TITLE} (updates)`,
+    description: `Recently updated: ${siteConfig.FEED_DESCRIPTION}`,
+    site: context.site,
+    customData: getChannelCustomData(),
+    items: posts.map(({ post, computed }) => ({
+      title: getPostTitle(post),
+      pubDate: new Date(computed.lastModified),
+      description: computed.commitHash ? `Updated in commit ${computed.commitHash}` : getPostSummary(post),
+      // The fragment keeps each revision distinct so feed readers surface
+      // a post again after it is edited.
+      link: `${getPostURL(post)}${computed.commitHash ? `#${computed.commitHash}` : ''}`,
+      author: getPostAuthors(post).join(', '),
+    })),
+  });
+}
@@ -1,13 +1,14 @@
 import type { APIRoute } from 'astro';
+import { withBase } from '../utils/urls.js';
+import siteConfig from '../../site.config.mjs';
 
 export const GET: APIRoute = ({ site }) => {
-  const sitemapURL = new URL('sitemap-index.xml', site);
+  const sitemapLine = siteConfig.SITEMAP ? `Sitemap: ${new URL(withBase('/sitemap-index.xml'), site)}\n` : '';
   
   return new Response(
     `User-agent: *
 Allow: /
-Sitemap: ${sitemapURL}
-`,
+${sitemapLine}`,
     {
       headers: {
         'Content-Type': 'text/plain',
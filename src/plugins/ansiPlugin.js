@@ -0,0 +1,16 @@
+import { visit } from 'unist-util-visit';
+
+const ESCAPE_SEQUENCE = /\u001b\[[0-9;]*m/;
+
+// Shiki renders the `ansi` language as colored spans at build time. Pasted
+// terminal output is usually tagged `console`, so route those blocks through
+// the same renderer when they actually contain escape codes.
+export function ansiPlugin() {
+  return (tree) => {
+    visit(tree, 'code', (node) => {
+      if (node.lang === 'console' && ESCAPE_SEQUENCE.test(node.value)) {
+        node.lang = 'ansi';
+      }
+    });
+  };
+}
@@ -0,0 +1,43 @@
+import { readFileSync } from 'fs';
+import { dirname, resolve } from 'path';
+import { visit } from 'unist-util-visit';
+import { withBase } from '../utils/urls.js';
+import { createLogger } from '../utils/log.js';
+
+const log = createLogger('asciinema');
+
+function escapeAttribute(value) {
+  return String(value).replace(/&/g, '&amp;').replace(/"/g, '&quot;');
+}
+
+// Replace ```asciinema blocks naming a cast file (relative to the post) with
+// an inline copy of the cast and the markup picked up by /js/asciinema.js.
+export function asciinemaPlugin() {
+  return (tree, file) => {
+    const sourcePath = file.path ?? file.history?.[0];
+    if (!sourcePath) return;
+
+    visit(tree, 'code', (node, index, parent) => {
+      if (node.lang !== 'asciinema') return;
+
+      const castPath = node.value.trim().split('\n')[0];
+      let cast;
+      try {
+        cast = readFileSync(resolve(dirname(sourcePath), castPath), 'utf-8');
+      } catch {
+        log.warn(`cast not found: ${castPath}`, sourcePath);
+        return;
+      }
+
+      parent.children[index] = {
+        type: 'html',
+        value: `<div class="asciinema-player" data-cast="${escapeAttribute(castPath)}">`
+          + '<pre class="asciinema-screen" tabindex="0"></pre>'
+          + '<button type="button" class="asciinema-toggle">▶ Play</button>'
+          + `<script type="application/x-asciicast">${cast.replace(/<\//g, '<\\/')}</script>`
+          + '</div>'
+          + `<script src="${withBase('/js/asciinema.js')}" defer></script>`,
+      };
+    });
+  };
+}
@@ -0,0 +1,135 @@
+import { load } from 'js-yaml';
+import { visit } from 'unist-util-visit';
+import { createLogger } from '../utils/log.js';
+
+const log = createLogger('chart');
+
+const WIDTH = 640;
+const HEIGHT = 320;
+const PADDING = { top: 32, right: 16, bottom: 48, left: 56 };
+const GRID_LINES = 5;
+
+function escapeXml(value) {
+  return String(value).replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;').replace(/"/g, '&quot;');
+}
+
+function niceMax(value) {
+  if (value <= 0) return 1;
+  const magnitude = 10 ** Math.floor(Math.log10(value));
+  const step = [1, 2, 2.5, 5, 10].find((candidate) => candidate * magnitude >= value);
+  return step * magnitude;
+}
+
+function formatNumber(value) {
+  return Number.isInteger(value) ? String(value) : value.toFixed(2).replace(/0+$/, '');
+}
+
+// The chart in a block, or an error naming what's wrong with it. JSON is
+// valid YAML, so both parse the same way.
+function parseChart(source) {
+  const chart = load(source);
+  if (!chart || typeof chart !== 'object' || Array.isArray(chart)) {
+    throw new Error('expected an object with labels and series');
+  }
+  if (chart.labels !== undefined && !Array.isArray(chart.labels)) {
+    throw new Error('labels must be a list');
+  }
+  if (!Array.isArray(chart.series) || chart.series.length === 0) {
+    throw new Error('series must be a non-empty list');
+  }
+  chart.series.forEach((s, index) => {
+    if (!s || !Array.isArray(s.data) || !s.data.every((value) => typeof value === 'number' && Number.isFinite(value))) {
+      throw new Error(`series ${index + 1} needs a data list of numbers`);
+    }
+  });
+  return chart;
+}
+
+function renderChart(chart) {
+  const labels = chart.labels ?? [];
+  const series = chart.series;
+  const unit = chart.unit ? ` ${chart.unit}` : '';
+  const plotWidth = WIDTH - PADDING.left - PADDING.right;
+  const plotHeight = HEIGHT - PADDING.top - PADDING.bottom;
+  const values = series.flatMap((s) => s.data);
+  // The axis always includes zero and grows downwards for negative values.
+  const highest = Math.max(0, ...values);
+  const lowest = Math.min(0, ...values);
+  const max = highest > 0 || lowest === 0 ? niceMax(highest) : 0;
+  const min = lowest < 0 ? -niceMax(-lowest) : 0;
+  const y = (value) => PADDING.top + plotHeight - ((value - min) / (max - min)) * plotHeight;
+  const slot = plotWidth / Math.max(1, labels.length, ...series.map((s) => s.data.length));
+
+  const parts = [];
+
+  for (let i = 0; i <= GRID_LINES; i++) {
+    const value = min + ((max - min) / GRID_LINES) * i;
+    parts.push(`<line class="chart-grid" x1="${PADDING.left}" x2="${WIDTH - PADDING.right}" y1="${y(value)}" y2="${y(value)}" />`);
+    parts.push(`<text class="chart-axis" x="${PADDING.left - 8}" y="${y(value) + 4}" text-anchor="end">${formatNumber(value)}</text>`);
+  }
+
+  labels.forEach((label, i) => {
+    const x = PADDING.left + slot * (i + 0.5);
+    parts.push(`<text class="chart-axis" x="${x}" y="${HEIGHT - PADDING.bottom + 20}" text-anchor="middle">${escapeXml(label)}</text>`);
+  });
+
+  if (min < 0) {
+    parts.push(`<line class="chart-zero" x1="${PADDING.left}" x2="${WIDTH - PADDING.right}" y1="${y(0)}" y2="${y(0)}" />`);
+  }
+
+  if (chart.type === 'line') {
+    series.forEach((s, index) => {
+      const points = s.data.map((value, i) => `${PADDING.left + slot * (i + 0.5)},${y(value)}`);
+      parts.push(`<polyline class="chart-line series-${index % 3}" points="${points.join(' ')}" />`);
+      s.data.forEach((value, i) => {
+        parts.push(`<circle class="chart-point series-${index % 3}" cx="${PADDING.left + slot * (i + 0.5)}" cy="${y(value)}" r="3"><title>${escapeXml(`${s.name ?? ''} ${labels[i] ?? ''}: ${value}${unit}`.trim())}</title></circle>`);
+      });
+    });
+  } else {
+    const barWidth = (slot * 0.8) / Math.max(1, series.length);
+    series.forEach((s, index) => {
+      s.data.forEach((value, i) => {
+        const x = PADDING.left + slot * i + slot * 0.1 + barWidth * index;
+        const top = Math.min(y(value), y(0));
+        parts.push(`<rect class="chart-bar series-${index % 3}" x="${x}" y="${top}" width="${barWidth}" height="${Math.abs(y(value) - y(0))}"><title>${escapeXml(`${s.name ?? ''} ${labels[i] ?? ''}: ${value}${unit}`.trim())}</title></rect>`);
+      });
+    });
+  }
+
+  if (chart.title) {
+    parts.push(`<text class="chart-title" x="${WIDTH / 2}" y="${PADDING.top / 2 + 4}" text-anchor="middle">${escapeXml(chart.title)}</text>`);
+  }
+
+  if (series.length > 1) {
+    series.forEach((s, index) => {
+      const x = PADDING.left + index * 120;
+      parts.push(`<rect class="chart-bar series-${index % 3}" x="${x}" y="${HEIGHT - 16}" width="10" height="10" />`);
+      parts.push(`<text class="chart-axis" x="${x + 14}" y="${HEIGHT - 7}">${escapeXml(s.name ?? `Series ${index + 1}`)}</text>`);
+    });
+  }
+
+  const label = chart.title ? escapeXml(chart.title) : 'Chart';
+  return `<figure class="chart"><svg viewBox="0 0 ${WIDTH} ${HEIGHT}" role="img" aria-label="${label}">${parts.join('')}</svg></figure>`;
+}
+
+// Render ```chart blocks holding JSON or YAML like
+// {"type": "bar", "labels": [...], "series": [{"name": "...", "data": [...]}]}
+// to an SVG bar or line chart at build time. Blocks that don't describe a
+// chart are left as code with a warning.
+export function chartPlugin() {
+  return (tree, file) => {
+    visit(tree, 'code', (node, index, parent) => {
+      if (node.lang !== 'chart') return;
+
+      let chart;
+      try {
+        chart = parseChart(node.value);
+      } catch (error) {
+        log.warn(`invalid chart data: ${error.message}`, file.path);
+        return;
+      }
+
+      parent.children[index] = { type: 'html', value: renderChart(chart) };
+    });
+  };
+}
@@ -0,0 +1,77 @@
+import siteConfig from '../../site.config.mjs';
+
+function getLanguage(pre) {
+  const language = pre.properties?.dataLanguage;
+  return language && language !== 'plaintext' ? String(language) : undefined;
+}
+
+function getTitle(pre) {
+  const title = pre.properties?.dataTitle;
+  return title ? String(title) : undefined;
+}
+
+function buildLabel(className, value) {
+  return {
+    type: 'element',
+    tagName: 'span',
+    properties: { className: [className] },
+    children: [{ type: 'text', value }],
+  };
+}
+
+function buildHeader(title, language) {
+  const children = [];
+  if (title) children.push(buildLabel('code-title', title));
+  if (language && siteConfig.CODE_LANGUAGE_LABEL) children.push(buildLabel('code-lang', language));
+
+  return {
+    type: 'element',
+    tagName: 'div',
+    properties: { className: ['code-header'] },
+    children,
+  };
+}
+
+// "Code: go, main.go", for screen readers landing on the block.
+function getAriaLabel(title, language) {
+  const details = [language, title].filter(Boolean);
+  return details.length > 0 ? `Code: ${details.join(', ')}` : 'Code';
+}
+
+function wrapCodeBlocks(node) {
+  if (!node.children) return;
+
+  node.children = node.children.map((child) => {
+    if (child.type !== 'element' || child.tagName !== 'pre') {
+      wrapCodeBlocks(child);
+      return child;
+    }
+
+    const language = getLanguage(child);
+    const title = getTitle(child);
+    const code = child.properties?.dataCode;
+    if (code !== undefined) delete child.properties.dataCode;
+    // The <pre> is what scrolls sideways, so it is the element keyboard users
+    // need to focus; a named region tells them what they landed on.
+    child.properties = { ...child.properties, tabIndex: 0, role: 'region', ariaLabel: getAriaLabel(title, language) };
+    const children = [child];
+    if (title || (siteConfig.CODE_LANGUAGE_LABEL && language)) {
+      children.unshift(buildHeader(title, language));
+    }
+
+    return {
+      type: 'element',
+      tagName: siteConfig.CODE_WRAPPER_ELEMENT,
+      properties: { className: [siteConfig.CODE_WRAPPER_CLASS], dataLang: language, dataTitle: title, dataCode: code },
+      children,
+    };
+  });
+}
+
+// Wrap highlighted code blocks so themes and the bundled script can style
+// them and read the language without inspecting the highlighter's markup.
+export function codeWrapperPlugin() {
+  return (tree) => {
+    wrapCodeBlocks(tree);
+  };
+}
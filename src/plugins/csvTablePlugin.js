@@ -0,0 +1,100 @@
+import { readFileSync } from 'fs';
+import { dirname, resolve } from 'path';
+import { visit } from 'unist-util-visit';
+import { parseFenceMeta } from '../utils/fenceMeta.js';
+import { createLogger } from '../utils/log.js';
+
+const log = createLogger('csvTable');
+
+const ALIGNMENTS = { l: 'left', c: 'center', r: 'right', left: 'left', center: 'center', right: 'right' };
+
+function escapeHtml(value) {
+  return value.replace(/&/g, '&amp;').replace(/</g, '&lt;').replace(/>/g, '&gt;');
+}
+
+// Parse delimited text, honouring double-quoted fields with embedded
+// delimiters, newlines and "" escapes.
+function parseDelimited(text, delimiter) {
+  const rows = [];
+  let row = [];
+  let field = '';
+  let quoted = false;
+
+  for (let i = 0; i < text.length; i++) {
+    const char = text[i];
+    if (quoted) {
+      if (char === '"' && text[i + 1] === '"') {
+        field += '"';
+        i++;
+      } else if (char === '"') {
+        quoted = false;
+      } else {
+        field += char;
+      }
+    } else if (char === '"' && field === '') {
+      quoted = true;
+    } else if (char === delimiter) {
+      row.push(field);
+      field = '';
+    } else if (char === '\n') {
+      row.push(field.replace(/\r$/, ''));
+      rows.push(row);
+      row = [];
+      field = '';
+    } else {
+      field += char;
+    }
+  }
+
+  if (field || row.length > 0) {
+    row.push(field.replace(/\r$/, ''));
+    rows.push(row);
+  }
+
+  return rows.filter((cells) => cells.some((cell) => cell.trim()));
+}
+
+function renderCells(cells, tag, align) {
+  return cells.map((cell, column) => {
+    const alignment = align[column] ? ` style="text-align: ${align[column]}"` : '';
+    return `<${tag}${alignment}>${escapeHtml(cell.trim())}</${tag}>`;
+  }).join('');
+}
+
+function renderTable(rows, options) {
+  const align = String(options.align || '').split(',').map((hint) => ALIGNMENTS[hint.trim()]);
+  const hasHeader = options.header !== 'false';
+  const [head, ...body] = hasHeader ? rows : [undefined, ...rows];
+
+  const thead = head ? `<thead><tr>${renderCells(head, 'th', align)}</tr></thead>` : '';
+  const tbody = body.map((cells) => `<tr>${renderCells(cells, 'td', align)}</tr>`).join('');
+
+  return `<div class="data-table"><table>${thead}<tbody>${tbody}</tbody></table></div>`;
+}
+
+// Render ```csv and ```tsv blocks as HTML tables. The block body holds the
+// data, or `src="file.csv"` in the fence points at a file next to the post.
+// `header=false` drops the header row and `align=l,c,r` aligns columns.
+export function csvTablePlugin() {
+  return (tree, file) => {
+    visit(tree, 'code', (node, index, parent) => {
+      if (node.lang !== 'csv' && node.lang !== 'tsv') return;
+
+      const options = parseFenceMeta(node.meta);
+      let text = node.value;
+      if (typeof options.src === 'string') {
+        try {
+          text = readFileSync(resolve(dirname(file.path), options.src), 'utf-8');
+        } catch {
+          log.warn(`file not found: ${options.src}`, file.path);
+          return;
+        }
+      }
+
+      const rows = parseDelimited(text, node.lang === 'tsv' ? '\t' : ',');
+      if (rows.length === 0) return;
+
+      parent.children[index] = { type: 'html', value: renderTable(rows, options) };
+    });
+  };
+}
@@ -0,0 +1,76 @@
+import { execFileSync } from 'child_process';
+import { createHash } from 'crypto';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import { visit } from 'unist-util-visit';
+import { writeFileAtomic } from '../utils/writeFileAtomic.js';
+import { createLogger } from '../utils/log.js';
+
+const log = createLogger('diagram');
+
+const CACHE_DIR = join(process.cwd(), 'node_modules/.cache/krea.to/diagrams');
+
+const RENDERERS = {
+  dot: { command: 'dot', args: ['-Tsvg'] },
+  graphviz: { command: 'dot', args: ['-Tsvg'] },
+  plantuml: { command: 'plantuml', args: ['-tsvg', '-pipe'] },
+  puml: { command: 'plantuml', args: ['-tsvg', '-pipe'] },
+};
+
+const missingCommands = new Set();
+
+function renderDiagram(renderer, source) {
+  const hash = createHash('sha256').update(`${renderer.command}\n${source}`).digest('hex');
+  const cachePath = join(CACHE_DIR, `${hash}.svg`);
+
+  try {
+    return readFileSync(cachePath, 'utf-8');
+  } catch {
+    // Not rendered yet.
+  }
+
+  const output = execFileSync(renderer.command, renderer.args, {
+    input: source,
+    encoding: 'utf-8',
+    stdio: ['pipe', 'pipe', 'pipe'],
+  });
+  // Drop the XML prolog and doctype so the SVG can be inlined.
+  const svg = output.slice(output.indexOf('<svg'));
+
+  try {
+    writeFileAtomic(cachePath, svg);
+  } catch {
+    // The cache is only an optimization.
+  }
+
+  return svg;
+}
+
+// Render ```dot and ```plantuml blocks to inline SVG with the locally
+// installed tools. Blocks stay as highlighted source when the tool is missing.
+export function diagramPlugin() {
+  return (tree, file) => {
+    visit(tree, 'code', (node, index, parent) => {
+      const renderer = RENDERERS[node.lang];
+      if (!renderer || missingCommands.has(renderer.command)) return;
+
+      let svg;
+      try {
+        svg = renderDiagram(renderer, node.value);
+      } catch (error) {
+        if (error.code === 'ENOENT') {
+          missingCommands.add(renderer.command);
+          log.warn(`${renderer.command} not found, leaving ${node.lang} blocks as code`);
+        } else {
+          log.warn(`failed to render ${node.lang} block: ${error.stderr || error.message}`, file.path);
+        }
+        return;
+      }
+
+      parent.children[index] = {
+        type: 'html',
+        value: `<figure class="diagram diagram-${node.lang}">${svg}</figure>`,
+      };
+    });
+  };
+}
@@ -0,0 +1,58 @@
+import { parseFenceMeta } from '../utils/fenceMeta.js';
+
+const optionsCache = new WeakMap();
+
+// Fence options are parsed once per code block; shiki calls the line hook
+// for every line with the same options object.
+function getOptions(context) {
+  let options = optionsCache.get(context.options);
+  if (!options) {
+    options = parseFenceMeta(context.options.meta?.__raw);
+    optionsCache.set(context.options, options);
+  }
+  return options;
+}
+
+// Parse `[3,5-7]` (or `3 5-7`) into the set of line numbers it covers.
+function parseLineRanges(value) {
+  const lines = new Set();
+  if (typeof value !== 'string') return lines;
+
+  for (const range of value.replace(/[[\]]/g, '').split(/[\s,]+/).filter(Boolean)) {
+    const [start, end = start] = range.split('-').map(Number);
+    for (let line = start; line <= end; line++) lines.add(line);
+  }
+  return lines;
+}
+
+// Shiki transformer for fence options:
+//
+//   ```go title="main.go" {hl_lines=[3,5-7], linenostart=10}
+//
+// hl_lines marks lines of the block (counted from 1) with a `highlighted`
+// class. linenos turns on line numbers; linenostart also does, starting
+// the count at the given number. title ends up in a data-title attribute,
+// which codeWrapperPlugin turns into a filename header.
+//
+// Every block also gets its unhighlighted source in data-code, so copy
+// buttons can copy the code without line numbers or markup.
+export function fenceOptionsTransformer() {
+  return {
+    name: 'krea:fence-options',
+    line(node, line) {
+      if (parseLineRanges(getOptions(this).hl_lines).has(line)) {
+        this.addClassToHast(node, 'highlighted');
+      }
+    },
+    pre(node) {
+      const options = getOptions(this);
+      node.properties.dataCode = this.source;
+      if (typeof options.title === 'string') node.properties.dataTitle = options.title;
+      if (!options.linenos && !options.linenostart) return;
+
+      const start = Number(options.linenostart) || 1;
+      this.addClassToHast(node, 'line-numbers');
+      node.properties.style = `${node.properties.style ?? ''};--line-start:${start - 1}`;
+    },
+  };
+}
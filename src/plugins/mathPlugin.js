@@ -0,0 +1,66 @@
+import { visit } from 'unist-util-visit';
+
+// KaTeX is optional: with it installed (`bun add katex`) math is rendered to
+// HTML at build time, otherwise it is left as `\(...\)` / `\[...\]` markup
+// that the KaTeX auto-render script picks up in the browser.
+let katex;
+try {
+  katex = (await import('katex')).default;
+} catch {
+  katex = undefined;
+}
+
+const DISPLAY_MATH = /^\$\$([\s\S]+)\$\$$/;
+const INLINE_MATH = /^\$([^$]+)\$$/;
+
+function escapeHtml(value) {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;');
+}
+
+function renderMath(tex, displayMode) {
+  const tag = displayMode ? 'div' : 'span';
+  const className = displayMode ? 'math math-display' : 'math math-inline';
+  const body = katex
+    ? katex.renderToString(tex, { displayMode, throwOnError: false })
+    : escapeHtml(displayMode ? `\\[${tex}\\]` : `\\(${tex}\\)`);
+  return `<${tag} class="${className}">${body}</${tag}>`;
+}
+
+// Math in three spellings: ```math blocks and paragraphs wrapped in `$$`
+// for display math, and inline code written as `$...$` for inline math.
+// Code keeps its contents verbatim; a `$$` paragraph is still markdown, so
+// formulas using `_`, `*` or `\\` belong in a ```math block.
+//
+// Pages with math get `math: true` in their remark frontmatter so the layout
+// can load KaTeX's stylesheet (and its auto-render script when KaTeX isn't
+// installed).
+export function mathPlugin() {
+  return (tree, file) => {
+    let hasMath = false;
+
+    visit(tree, (node, index, parent) => {
+      let html;
+      if (node.type === 'code' && node.lang === 'math') {
+        html = renderMath(node.value, true);
+      } else if (node.type === 'inlineCode' && INLINE_MATH.test(node.value)) {
+        html = renderMath(node.value.match(INLINE_MATH)[1], false);
+      } else if (node.type === 'paragraph' && node.children.length === 1 && node.children[0].type === 'text') {
+        const match = node.children[0].value.trim().match(DISPLAY_MATH);
+        if (match) html = renderMath(match[1].trim(), true);
+      }
+
+      if (html) {
+        parent.children[index] = { type: 'html', value: html };
+        hasMath = true;
+      }
+    });
+
+    if (hasMath && file.data.astro?.frontmatter) {
+      file.data.astro.frontmatter.math = true;
+      file.data.astro.frontmatter.mathRendered = Boolean(katex);
+    }
+  };
+}
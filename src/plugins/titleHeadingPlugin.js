@@ -0,0 +1,24 @@
+import { visit } from 'unist-util-visit';
+import siteConfig from '../../site.config.mjs';
+
+// Posts often open with their title as an H1, which the post layout already
+// renders above the content. With STRIP_TITLE_H1 on, drop that leading H1.
+// HEADING_OFFSET then demotes the remaining headings (an offset of 1 turns
+// H1s into H2s), so the layout's title stays the page's only H1.
+export function titleHeadingPlugin() {
+  return (tree) => {
+    if (siteConfig.STRIP_TITLE_H1) {
+      const first = tree.children.findIndex((node) => node.type !== 'yaml' && node.type !== 'html');
+      const node = tree.children[first];
+      if (node?.type === 'heading' && node.depth === 1) {
+        tree.children.splice(first, 1);
+      }
+    }
+
+    if (siteConfig.HEADING_OFFSET > 0) {
+      visit(tree, 'heading', (node) => {
+        node.depth = Math.min(node.depth + siteConfig.HEADING_OFFSET, 6);
+      });
+    }
+  };
+}
@@ -0,0 +1,167 @@
+import { readdirSync } from 'fs';
+import { slug } from 'github-slugger';
+import { basename, dirname, join, relative, sep } from 'path';
+import { SKIP, visit } from 'unist-util-visit';
+import { isExcludedPath, normalizeName, splitContentPath } from '../utils/contentPaths';
+import { isInside } from '../utils/outputPaths';
+import { WIKI_LINK, getPostComputedMetadataById, resolveWikiLink } from '../utils/postMetadata';
+import { pageURL, withBase } from '../utils/urls.js';
+import { createLogger } from '../utils/log.js';
+import siteConfig from '../../site.config.mjs';
+
+const log = createLogger('wikiLink');
+
+// A wiki link, or with a leading `!` an embed: ![[photo.png]]
+const WIKI_TOKEN = new RegExp(`(!?)${WIKI_LINK.source}`, 'g');
+
+const assetIndexes = new Map();
+
+// Every file below a content root by name, for resolving embeds the way
+// Obsidian does: by file name alone, wherever in the vault the file is.
+// `folded` holds the same files by lowercased name. Build output and tooling
+// directories are skipped like they are for posts, and symlinks aren't
+// followed, so a root of '.' doesn't index node_modules and a link to a
+// parent directory can't loop.
+function getAssetIndex(root) {
+  let index = assetIndexes.get(root);
+  if (!index) {
+    index = { exact: new Map(), folded: new Map() };
+    const walk = (dir) => {
+      for (const entry of readdirSync(dir, { withFileTypes: true })) {
+        const fullPath = join(dir, entry.name);
+        if (isExcludedPath(relative(process.cwd(), fullPath).split(sep).join('/'))) continue;
+        if (entry.isDirectory()) {
+          walk(fullPath);
+          continue;
+        }
+        if (!entry.isFile()) continue;
+        const name = normalizeName(entry.name);
+        if (!index.exact.has(name)) index.exact.set(name, fullPath);
+        if (!index.folded.has(name.toLowerCase())) index.folded.set(name.toLowerCase(), fullPath);
+      }
+    };
+    walk(join(process.cwd(), root));
+    assetIndexes.set(root, index);
+  }
+  return index;
+}
+
+// The file at `path`, spelled exactly or, with `ignoreCase`, in any case.
+// existsSync alone would accept a wrong-case name on macOS and Windows and
+// only break once the site builds on a case-sensitive file system.
+function findFile(path, ignoreCase) {
+  let entries;
+  try {
+    entries = readdirSync(dirname(path)).map(normalizeName);
+  } catch {
+    return undefined;
+  }
+  const name = normalizeName(basename(path));
+  const match = ignoreCase
+    ? entries.find(entry => entry.toLowerCase() === name.toLowerCase())
+    : entries.find(entry => entry === name);
+  return match && join(dirname(path), match);
+}
+
+// Find an embedded file next to the post first, then anywhere in its root.
+// Paths like ![[../../secret.png]] that leave the content root (the project,
+// for pages outside one) match nothing, since whatever they name would be
+// published with the post.
+function resolveAsset(target, postPath, ignoreCase) {
+  const root = splitContentPath(relative(process.cwd(), postPath).split(sep).join('/'))?.root;
+  const besidePost = findFile(join(dirname(postPath), target), ignoreCase);
+  if (besidePost) return isInside(join(process.cwd(), root ?? '.'), besidePost) ? besidePost : undefined;
+
+  if (!root) return undefined;
+  const name = normalizeName(basename(target));
+  const index = getAssetIndex(root);
+  return ignoreCase ? index.folded.get(name.toLowerCase()) : index.exact.get(name);
+}
+
+// An image node with a path relative to the post, so Astro's image pipeline
+// copies (and optimizes) the file like any other markdown image.
+function buildEmbed(target, alt, file) {
+  if (!file.path) return { type: 'text', value: `![[${target}]]` };
+
+  let assetPath = resolveAsset(target.trim(), file.path, false);
+  if (!assetPath) {
+    const caseMismatch = resolveAsset(target.trim(), file.path, true);
+    if (caseMismatch && siteConfig.CASE_INSENSITIVE_LINKS) {
+      log.warn(`![[${target}]] only matches ${basename(caseMismatch)} ignoring case; fix the spelling before it breaks elsewhere`, file.path);
+      assetPath = caseMismatch;
+    } else {
+      log.warn(`no file matches ![[${target}]]${caseMismatch ? ` (${basename(caseMismatch)} differs in case)` : ''}`, file.path);
+      return { type: 'text', value: `![[${target}]]` };
+    }
+  }
+
+  const url = relative(dirname(file.path), assetPath).split(sep).join('/');
+  return { type: 'image', url: url.startsWith('.') ? url : `./${url}`, alt };
+}
+
+// The same slugger Astro uses for heading ids, so anchors match.
+function getHeadingAnchor(heading) {
+  return heading ? `#${slug(heading.trim())}` : '';
+}
+
+function buildLink(target, label, file) {
+  const [page, heading] = target.split('#', 2);
+  const anchor = getHeadingAnchor(heading);
+
+  // [[#Heading]] links within the current post.
+  if (!page.trim()) {
+    return {
+      type: 'link',
+      url: anchor,
+      children: [{ type: 'text', value: label }],
+      data: { hProperties: { className: ['wikilink'] } },
+    };
+  }
+
+  const id = resolveWikiLink(page.trim());
+  if (!id) {
+    log.warn(`no post matches [[${target}]]`, file.path);
+    return {
+      type: 'html',
+      value: `<span class="wikilink wikilink-missing" title="No post matches this link">${label.replace(/&/g, '&amp;').replace(/</g, '&lt;')}</span>`,
+    };
+  }
+
+  return {
+    type: 'link',
+    url: pageURL(withBase(`${getPostComputedMetadataById(id).path}${anchor}`)),
+    children: [{ type: 'text', value: label }],
+    data: { hProperties: { className: ['wikilink'] } },
+  };
+}
+
+// Turn `[[Post Name]]` and `[[Post Name|label]]` into links to other posts.
+// `[[Post Name#Heading]]` links to a heading in that post and `[[#Heading]]`
+// to one in the current post. Without a label the target itself is shown.
+// `![[photo.png]]` (or `![[photo.png|alt text]]`) embeds an image.
+export function wikiLinkPlugin() {
+  return (tree, file) => {
+    visit(tree, (node, index, parent) => {
+      // Links already have a target; code nodes have no text children.
+      if (node.type === 'link' || node.type === 'linkReference') return SKIP;
+      if (node.type !== 'text' || !node.value.includes('[[')) return;
+
+      const nodes = [];
+      let last = 0;
+      for (const match of node.value.matchAll(WIKI_TOKEN)) {
+        const [source, embed, target, label] = match;
+        if (match.index > last) nodes.push({ type: 'text', value: node.value.slice(last, match.index) });
+        nodes.push(embed
+          ? buildEmbed(target, (label ?? basename(target)).trim(), file)
+          : buildLink(target, (label ?? target).trim(), file));
+        last = match.index + source.length;
+      }
+      if (nodes.length === 0) return;
+      if (last < node.value.length) nodes.push({ type: 'text', value: node.value.slice(last) });
+
+      parent.children.splice(index, 1, ...nodes);
+      // Carry on after the new nodes rather than inside them.
+      return index + nodes.length;
+    });
+  };
+}
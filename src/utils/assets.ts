@@ -0,0 +1,29 @@
+import { createHash } from 'crypto';
+import { readFileSync } from 'fs';
+import siteConfig from '../../site.config.mjs';
+import { listPublicFiles, resolvePublicFile } from './themes';
+
+// Directories below public/ whose files templates link with a version.
+const VERSIONED_DIRS = ['css', 'js'];
+
+// One hash over the site's stylesheets and scripts, THEME_DIRS' included. It
+// only changes when one of them does, so deploys that touch nothing but posts
+// keep browser caches.
+function getAssetVersion(): string {
+  const hash = createHash('sha256');
+  for (const directory of VERSIONED_DIRS) {
+    for (const file of listPublicFiles(directory)) {
+      hash.update(file).update(readFileSync(resolvePublicFile(file)!));
+    }
+  }
+  return hash.digest('hex').slice(0, 10);
+}
+
+export const ASSET_VERSION: string | undefined = siteConfig.ASSET_VERSIONING ? getAssetVersion() : undefined;
+
+// `path` with a `?v=` cache-busting query when ASSET_VERSIONING is on.
+// Only site-local paths are versioned.
+export function assetURL(path: string): string {
+  if (!ASSET_VERSION || !path.startsWith('/') || path.startsWith('//')) return path;
+  return `${path}${path.includes('?') ? '&' : '?'}v=${ASSET_VERSION}`;
+}
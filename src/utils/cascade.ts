@@ -0,0 +1,64 @@
+import { existsSync, readFileSync } from 'fs';
+import { dirname, join } from 'path';
+import { parseFrontmatter } from '@astrojs/markdown-remark';
+import type { Loader } from 'astro/loaders';
+import { normalizeMetadataKeys } from './commentMetadata';
+import { splitContentPath } from './contentPaths';
+
+const cascadeCache = new Map<string, Record<string, unknown>>();
+
+// The `cascade` map of the `_index.md` in a directory, if any. Its keys are
+// spelled like a post's own and must be among `keys`: with the strict blog
+// schema, one unknown key would otherwise fail every post below the section
+// with an error that doesn't say where the key came from.
+function readSectionCascade(directory: string, keys: Set<string>): Record<string, unknown> {
+  let cascade = cascadeCache.get(directory);
+  if (!cascade) {
+    const indexPath = join(directory, '_index.md');
+    const frontmatter = existsSync(indexPath)
+      ? parseFrontmatter(readFileSync(indexPath, 'utf-8')).frontmatter
+      : {};
+    cascade = normalizeMetadataKeys((frontmatter.cascade ?? {}) as Record<string, unknown>, indexPath);
+    const unknown = Object.keys(cascade).filter(key => !keys.has(key));
+    if (unknown.length > 0) {
+      throw new Error(`${indexPath}: cascade sets ${unknown.join(', ')}, which ${unknown.length === 1 ? 'is not a metadata key' : 'are not metadata keys'}`);
+    }
+    cascadeCache.set(directory, cascade);
+  }
+  return cascade;
+}
+
+// Metadata a post inherits from the sections above it, nearest section
+// first, up to its content root.
+export function getCascade(filePath: string, keys: Set<string>): Record<string, unknown> {
+  const root = splitContentPath(filePath)?.root;
+  if (!root) return {};
+
+  const directories: string[] = [];
+  for (let directory = dirname(filePath); ; directory = dirname(directory)) {
+    directories.push(directory);
+    if (directory === root || directory === '.') break;
+  }
+
+  return Object.assign({}, ...directories.reverse().map(directory => readSectionCascade(directory, keys)));
+}
+
+// Fill in metadata a post doesn't set itself from its sections' `cascade`,
+// which may only set the given metadata `keys`.
+export function withCascade(loader: Loader, keys: string[]): Loader {
+  const known = new Set(keys);
+  return {
+    ...loader,
+    load: (context) => loader.load({
+      ...context,
+      parseData: (props) => {
+        if (!props.filePath) return context.parseData(props);
+
+        return context.parseData({
+          ...props,
+          data: { ...getCascade(props.filePath, known), ...props.data },
+        });
+      },
+    }),
+  };
+}
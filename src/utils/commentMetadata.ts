@@ -0,0 +1,99 @@
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import type { Loader } from 'astro/loaders';
+import { createLogger } from './log.js';
+
+const log = createLogger('metadata');
+
+// Metadata keys as the collection schemas spell them, plus common alternative
+// names from other generators. Matching ignores case, `-` and `_`.
+const CANONICAL_KEYS = [
+  'slug', 'title', 'description', 'author', 'authors', 'date', 'updated', 'tags', 'category', 'series', 'seriesPart',
+  'template', 'settings',
+  'commitHash', 'commitDate', 'commitAuthor', 'readTime', 'syndication', 'index', 'cascade',
+  'aliases', 'extraCSS', 'image', 'url',
+];
+const KEY_ALIASES: Record<string, string> = {
+  summary: 'description',
+  excerpt: 'description',
+  lastmod: 'updated',
+  modified: 'updated',
+  published: 'date',
+  keywords: 'tags',
+  outputpath: 'url',
+};
+
+function normalizeKey(key: string): string {
+  const folded = key.toLowerCase().replace(/[-_]/g, '');
+  return KEY_ALIASES[folded]
+    ?? CANONICAL_KEYS.find(canonical => canonical.toLowerCase() === folded)
+    ?? key;
+}
+
+// Rewrite metadata keys to their canonical spelling, so `Title:`, `read_time`
+// or `summary` land on the schema's `title`, `readTime` and `description`.
+// When both a key and its alias are set, the canonical key wins.
+export function normalizeMetadataKeys(data: Record<string, unknown>, source?: string): Record<string, unknown> {
+  const normalized: Record<string, unknown> = {};
+  for (const [key, value] of Object.entries(data)) {
+    const canonical = normalizeKey(key);
+    if (canonical !== key) {
+      log.debug(`reading "${key}" as "${canonical}"`, source);
+    }
+    if (canonical === key || !(canonical in normalized)) normalized[canonical] = value;
+  }
+  return normalized;
+}
+
+// Keys whose comma-separated comment values become lists.
+const LIST_KEYS = new Set(['tags', 'authors', 'syndication', 'aliases', 'extraCSS']);
+
+// Parse a leading multi-line `<!-- Key: value -->` block (after any YAML
+// frontmatter) into frontmatter-shaped data. Keys are normalized so
+// `Title:` maps onto the schema's `title`.
+export function readCommentMetadata(source: string): Record<string, unknown> {
+  const body = source.replace(/^---\r?\n[\s\S]*?\r?\n---\r?\n/, '');
+  const block = body.match(/^\s*<!--[ \t]*\r?\n([\s\S]*?)\r?\n\s*-->/);
+  if (!block) return {};
+
+  const data: Record<string, unknown> = {};
+  for (const line of block[1].split(/\r?\n/)) {
+    const match = line.match(/^\s*([A-Za-z][\w-]*)\s*:\s*(.*?)\s*$/);
+    if (!match) continue;
+
+    const key = normalizeKey(match[1]);
+    const value = match[2];
+    const items = value.split(',').map(item => item.trim()).filter(Boolean);
+
+    if (LIST_KEYS.has(key)) {
+      data[key] = items;
+    } else if (key === 'settings') {
+      data[key] = Object.fromEntries(items.map(item => [item, true]));
+    } else {
+      data[key] = value;
+    }
+  }
+
+  return data;
+}
+
+// Let a loader's entries declare metadata in either format. YAML frontmatter
+// wins when a key appears in both; keys are normalized and the merged data
+// still goes through the collection schema.
+export function withCommentMetadata(loader: Loader): Loader {
+  return {
+    ...loader,
+    load: (context) => loader.load({
+      ...context,
+      parseData: (props) => {
+        if (!props.filePath) return context.parseData(props);
+
+        const source = readFileSync(join(process.cwd(), props.filePath), 'utf-8');
+        return context.parseData({
+          ...props,
+          data: { ...readCommentMetadata(source), ...normalizeMetadataKeys(props.data, props.filePath) },
+        });
+      },
+    }),
+  };
+}
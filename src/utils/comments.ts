@@ -0,0 +1,78 @@
+import siteConfig from '../../site.config.mjs';
+import { OfflineError, request } from './http.js';
+import { createLogger } from './log.js';
+
+const log = createLogger('comments');
+
+// Discussion counts fetched once per build from the GitHub Discussions that
+// back giscus, keyed by the discussion title. With giscus' "pathname"
+// mapping that title is the page path without its leading slash.
+
+export interface CommentCount {
+  comments: number;
+  // The discussion on GitHub, as the site itself has no comment section.
+  url: string;
+}
+
+const QUERY = `query($owner: String!, $name: String!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    discussions(first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes {
+        title
+        url
+        category { name }
+        comments { totalCount }
+      }
+    }
+  }
+}`;
+
+// Responses are cached for COMMENTS_CACHE_TTL minutes. Without a token only
+// the cache is read.
+async function fetchCounts(repo: string, token: string | undefined): Promise<Record<string, CommentCount>> {
+  const [owner, name] = repo.split('/');
+  const counts: Record<string, CommentCount> = {};
+  let cursor: string | null = null;
+
+  do {
+    const response = await request('https://api.github.com/graphql', {
+      method: 'POST',
+      headers: { Authorization: `bearer ${token}`, 'Content-Type': 'application/json' },
+      body: JSON.stringify({ query: QUERY, variables: { owner, name, cursor } }),
+    }, { retries: 3, cacheTTL: siteConfig.COMMENTS_CACHE_TTL, cacheOnly: !token });
+    if (!response.ok) throw new Error(`GitHub API returned ${response.status}`);
+
+    const { data } = await response.json();
+    const discussions = data.repository.discussions;
+    for (const node of discussions.nodes) {
+      if (siteConfig.COMMENTS_CATEGORY && node.category.name !== siteConfig.COMMENTS_CATEGORY) continue;
+      counts[node.title] = { comments: node.comments.totalCount, url: node.url };
+    }
+    cursor = discussions.pageInfo.hasNextPage ? discussions.pageInfo.endCursor : null;
+  } while (cursor);
+
+  return counts;
+}
+
+async function loadCommentCounts(): Promise<Record<string, CommentCount>> {
+  const repo = siteConfig.COMMENTS_REPO;
+  if (!repo) return {};
+
+  try {
+    return await fetchCounts(repo, process.env.GITHUB_TOKEN);
+  } catch (error) {
+    // Offline or without a token, a missing cache just means no counts.
+    if (!(error instanceof OfflineError)) {
+      log.warn(`could not fetch discussion counts: ${(error as Error).message}`);
+    }
+    return {};
+  }
+}
+
+const COMMENT_COUNTS = await loadCommentCounts();
+
+// Counts for the discussion belonging to a page path like `/blog/nim/foo/`.
+export function getCommentCount(pathname: string): CommentCount | undefined {
+  return COMMENT_COUNTS[pathname.replace(/^\//, '')];
+}
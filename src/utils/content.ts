@@ -1,7 +1,10 @@
-import { getCollection } from 'astro:content';
+import { getCollection, getEntry } from 'astro:content';
 import type { CollectionEntry } from 'astro:content';
-import { marked } from 'marked';
+import { marked, type Tokens } from 'marked';
 import { getPostComputedMetadataById } from './postMetadata';
+import { getPostPath, slugifySegment } from './contentPaths';
+import { pageURL, withBase } from './urls.js';
+import siteConfig from '../../site.config.mjs';
 
 export async function getLandingPage(): Promise<CollectionEntry<'landing'>> {
   const landing = await getCollection('landing');
@@ -11,19 +14,200 @@ export async function getLandingPage(): Promise<CollectionEntry<'landing'>> {
   return landing[0];
 }
 
-export async function getRecentPosts(limit: number = 5): Promise<Array<{ title: string; link: string; commitHash?: string; commitURL?: string }>> {
-  const posts = await getCollection('blog');
-  posts.sort((a, b) => (b.data.date?.valueOf() || 0) - (a.data.date?.valueOf() || 0));
-  
-  return posts.slice(0, limit).map(post => {
-    const metadata = getPostComputedMetadataById(post.id);
-    return {
-      title: getPostTitle(post),
-      link: `/blog/${post.id.replace(/\.md$/, '')}/`,
-      commitHash: metadata?.commitHash,
-      commitURL: metadata?.commitURL
-    };
+export interface TagInfo {
+  name: string;
+  description?: string;
+  order?: number;
+}
+
+// Details from src/content/tags.yaml for every tag; tags it doesn't list
+// are shown as they are written.
+export async function getTagInfo(tag: string): Promise<TagInfo> {
+  const entry = await getEntry('tags', tag);
+  return { name: entry?.data.name ?? tag, description: entry?.data.description, order: entry?.data.order };
+}
+
+// Tags with an `order` come first, the rest alphabetically.
+export function compareTags(a: { tag: string; info: TagInfo }, b: { tag: string; info: TagInfo }): number {
+  return (a.info.order ?? Infinity) - (b.info.order ?? Infinity) || a.tag.localeCompare(b.tag);
+}
+
+// Everyone who wrote a post: its `authors`, or else its single `author`.
+export function getPostAuthors(entry: CollectionEntry<'blog'>): string[] {
+  return entry.data.authors.length > 0 ? entry.data.authors : [entry.data.author];
+}
+
+// Posts sharing at least `minSharedTags` tags with `entry`, the most shared
+// tags first and newest first among equals, for the "Related posts" list.
+export async function getRelatedPosts(entry: CollectionEntry<'blog'>, limit: number, minSharedTags: number = 1): Promise<CollectionEntry<'blog'>[]> {
+  if (limit <= 0 || entry.data.tags.length === 0) return [];
+
+  const tags = new Set(entry.data.tags);
+  return sortPostsByDate(await getCollection('blog'))
+    .filter(post => post.id !== entry.id)
+    .map(post => ({ post, score: post.data.tags.filter(tag => tags.has(tag)).length }))
+    .filter(({ score }) => score >= Math.max(minSharedTags, 1))
+    // Stable, so posts with the same score stay newest first.
+    .sort((a, b) => b.score - a.score)
+    .slice(0, limit)
+    .map(({ post }) => post);
+}
+
+// The most used tags, most used first; ties keep alphabetical order.
+export async function getPopularTags(limit: number): Promise<Array<{ name: string; count: number }>> {
+  const counts = new Map<string, number>();
+  for (const post of await getCollection('blog')) {
+    for (const tag of post.data.tags) counts.set(tag, (counts.get(tag) || 0) + 1);
+  }
+
+  return Array.from(counts, ([name, count]) => ({ name, count }))
+    .sort((a, b) => b.count - a.count || a.name.localeCompare(b.name))
+    .slice(0, limit);
+}
+
+// Page path of a post, below BASE_PATH. The entry id already reflects any
+// custom slug; a `url` replaces it altogether.
+export function getPostURL(entry: CollectionEntry<'blog'>): string {
+  return pageURL(withBase(getPostPath(entry.id, entry.data.url)));
+}
+
+export interface Category {
+  slug: string;
+  name: string;
+  description?: string;
+  // Newest first.
+  posts: CollectionEntry<'blog'>[];
+  latestPost?: CollectionEntry<'blog'>;
+  latestDate?: Date;
+  section?: CollectionEntry<'sections'>;
+}
+
+// Undo the escaping marked applies to text, for HTML it rendered that has
+// been stripped down to plain text (titles, summaries), so "Don't" doesn't
+// come out as "Don&#39;t".
+function decodeEntities(text: string): string {
+  return text.replace(/&(amp|lt|gt|quot|#39);/g, (entity, name) => ({ amp: '&', lt: '<', gt: '>', quot: '"', '#39': "'" })[name as string] ?? entity);
+}
+
+// First prose paragraph of a markdown body as plain text.
+function getFirstParagraph(markdown: string): string | undefined {
+  const paragraph = markdown
+    .split(/\n\s*\n/)
+    .map(block => block.trim())
+    .find(block => block && !/^(#|<!--|```|[-*>|])/.test(block));
+  if (!paragraph) return undefined;
+
+  return decodeEntities((marked.parseInline(paragraph) as string).replace(/<[^>]*>/g, '')).replace(/\s+/g, ' ').trim();
+}
+
+// A post's category: its `category` metadata, or else the top-level
+// directory it sits in. Posts at the top level without one have none.
+export function getPostCategorySlug(entry: CollectionEntry<'blog'>): string | undefined {
+  if (entry.data.category) return slugifySegment(entry.data.category);
+  const parts = entry.id.split('/');
+  return parts.length >= 2 ? parts[0] : undefined;
+}
+
+// Categories (see getPostCategorySlug), with their posts and the optional
+// `_index.md` section of the directory with the same name.
+export async function getCategories(): Promise<Category[]> {
+  const posts = sortPostsByDate(await getCollection('blog'));
+  const sections = new Map((await getCollection('sections')).map(section => [section.id, section]));
+  const categories = new Map<string, Category>();
+
+  posts.forEach(post => {
+    const slug = getPostCategorySlug(post);
+    if (!slug) return;
+
+    let category = categories.get(slug);
+    if (!category) {
+      const section = sections.get(slug);
+      // The source directory keeps its original casing; only trust it for
+      // posts sitting directly in the category directory.
+      const directory = !post.data.category && post.id.split('/').length === 2
+        ? getPostComputedMetadataById(post.id)?.originalDirectory
+        : undefined;
+      category = {
+        slug,
+        name: section?.data.title || post.data.category || directory || `${slug.charAt(0).toUpperCase()}${slug.slice(1)}`,
+        description: section?.data.description || (section?.body ? getFirstParagraph(section.body) : undefined),
+        posts: [],
+        section,
+      };
+      categories.set(slug, category);
+    }
+    category.posts.push(post);
   });
+
+  return Array.from(categories.values()).map(category => ({
+    ...category,
+    latestPost: category.posts[0],
+    latestDate: category.posts[0] ? getPostDate(category.posts[0]) : undefined,
+  }));
+}
+
+const MORE_MARKER = /<!--\s*more\s*-->/i;
+
+// Readable text of a markdown body, leaving out comments, code blocks and
+// headings.
+function getPlainText(markdown: string): string {
+  const prose = markdown
+    .replace(/<!--[\s\S]*?-->/g, '')
+    .replace(/^(`{3,}|~{3,})[\s\S]*?^\1/gm, '')
+    .replace(/^#{1,6}\s.*$/gm, '');
+  return decodeEntities((marked.parse(prose, { async: false }) as string).replace(/<[^>]*>/g, ' '))
+    .replace(/\s+/g, ' ')
+    .trim();
+}
+
+const summaries = new Map<string, string | undefined>();
+
+// Teaser for listings and feeds: the text before a `<!-- more -->` marker,
+// else the `description`, else the first SUMMARY_WORDS words of the post.
+export function getPostSummary(entry: CollectionEntry<'blog'>): string | undefined {
+  if (summaries.has(entry.id)) return summaries.get(entry.id);
+
+  const body = entry.body ?? '';
+  const marker = body.search(MORE_MARKER);
+  let summary = marker >= 0 ? getPlainText(body.slice(0, marker)) || undefined : undefined;
+  summary ??= entry.data.description;
+  if (!summary && siteConfig.SUMMARY_WORDS > 0) {
+    const words = getPlainText(body).split(' ').filter(Boolean);
+    if (words.length > 0) {
+      summary = words.length > siteConfig.SUMMARY_WORDS
+        ? `${words.slice(0, siteConfig.SUMMARY_WORDS).join(' ')}…`
+        : words.join(' ');
+    }
+  }
+
+  summaries.set(entry.id, summary);
+  return summary;
+}
+
+// Whether a category gets a generated index page.
+export function hasCategoryIndex(category: Category): boolean {
+  return category.section?.data.index ?? true;
+}
+
+// Effective post date: frontmatter date first, then the last commit, then the
+// file's modification time when the content isn't in a git repository.
+export function getPostDate(entry: CollectionEntry<'blog'>): Date | undefined {
+  if (entry.data.date) {
+    return entry.data.date;
+  }
+  const metadata = getPostComputedMetadataById(entry.id);
+  return metadata?.lastModified ? new Date(metadata.lastModified) : undefined;
+}
+
+// Reading time label: set in metadata, or estimated by readingTimePlugin
+// while rendering.
+export function getReadTime(entry: CollectionEntry<'blog'>): string | undefined {
+  return entry.data.readTime ?? entry.rendered?.metadata?.frontmatter?.readTime as string | undefined;
+}
+
+// Sort posts newest first, in place.
+export function sortPostsByDate(posts: CollectionEntry<'blog'>[]): CollectionEntry<'blog'>[] {
+  return posts.sort((a, b) => (getPostDate(b)?.valueOf() || 0) - (getPostDate(a)?.valueOf() || 0));
 }
 
 // Get title from slug (which is the filename without extension)
@@ -33,7 +217,18 @@ export function getTitleFromSlug(slug: string): string {
   return parts[parts.length - 1];
 }
 
-// Get post title: use frontmatter title if provided, otherwise derive from originalFilename
+// File names that say nothing about the post, like page bundles' index.md.
+const GENERIC_FILE_NAMES = new Set(['index', 'readme', 'post', 'untitled']);
+
+// Text of the first level-one heading in a markdown body.
+function getFirstHeading(markdown: string): string | undefined {
+  const heading = marked.lexer(markdown)
+    .find((token): token is Tokens.Heading => token.type === 'heading' && token.depth === 1);
+  return heading ? decodeEntities((marked.parseInline(heading.text) as string).replace(/<[^>]*>/g, '')).trim() : undefined;
+}
+
+// Get post title: use frontmatter title if provided, otherwise derive from
+// the file name, falling back to the first H1 when the file name is generic
 export function getPostTitle(entry: CollectionEntry<'blog'>): string {
   if (entry.data.title) {
     return entry.data.title;
@@ -42,9 +237,13 @@ export function getPostTitle(entry: CollectionEntry<'blog'>): string {
     return entry.data.originalFilename.replace(/\.md$/, '');
   }
   const metadata = getPostComputedMetadataById(entry.id);
-  if (metadata?.title) {
+  if (metadata?.title && !GENERIC_FILE_NAMES.has(metadata.title.toLowerCase())) {
     return metadata.title;
   }
+  const heading = entry.body && getFirstHeading(entry.body);
+  if (heading) {
+    return heading;
+  }
 
   // Final fallback: convert slug to title (replace hyphens with spaces and capitalize)
   const basename = entry.id.split('/').pop()?.replace(/\.md$/, '') || 'Untitled';
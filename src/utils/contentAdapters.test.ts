@@ -0,0 +1,31 @@
+import { describe, expect, test } from 'bun:test';
+import type { Loader } from 'astro/loaders';
+import { withContentAdapters, type GeneratedPost } from './contentAdapters';
+
+// Runs `posts` through an adapter and returns the ids stored and the
+// warnings logged.
+async function load(posts: GeneratedPost[]): Promise<{ ids: string[]; warnings: string[] }> {
+  const entries = new Map<string, unknown>();
+  const warnings: string[] = [];
+  const loader = withContentAdapters({ name: 'test', load: async () => {} }, [() => posts]);
+  await loader.load({
+    logger: { info: () => {}, warn: (message: string) => warnings.push(message) },
+    store: { has: (id: string) => entries.has(id), set: (entry: { id: string }) => entries.set(entry.id, entry) },
+    parseData: async ({ data }: { data: unknown }) => data,
+    renderMarkdown: async () => ({ html: '' }),
+    generateDigest: () => 'digest',
+  } as unknown as Parameters<Loader['load']>[0]);
+  return { ids: [...entries.keys()], warnings };
+}
+
+describe('withContentAdapters', () => {
+  test.each([
+    ['without a slug', { title: 'No slug' }],
+    ['with an empty slug', { slug: '  ', title: 'Empty slug' }],
+    ['with a slug leaving the blog', { slug: 'books/../../secret', title: 'Escape' }],
+  ])('skips a post %s and keeps the rest', async (_, post) => {
+    const { ids, warnings } = await load([post as GeneratedPost, { slug: 'books/dune', title: 'Dune' }]);
+    expect(ids).toEqual(['books/dune']);
+    expect(warnings).toHaveLength(1);
+  });
+});
@@ -0,0 +1,60 @@
+import type { Loader } from 'astro/loaders';
+import { normalizeMetadataKeys } from './commentMetadata';
+import { getEntryId } from './contentPaths';
+
+// A post that doesn't come from a markdown file: `slug` (its id), a
+// markdown `body`, and any other blog metadata (`title`, `date`, `tags`...).
+export interface GeneratedPost {
+  slug: string;
+  body?: string;
+  [key: string]: unknown;
+}
+
+// Plugins' `contentAdapters`: functions returning posts to add to the blog,
+// e.g. one per item of a JSON dataset. They run on every content sync.
+export type ContentAdapter = (context: { logger: { info(message: string): void; warn(message: string): void } }) =>
+  GeneratedPost[] | Promise<GeneratedPost[]>;
+
+// Add the posts `adapters` return to a loader's entries. They go through the
+// collection schema like any post and are rendered with the site's markdown
+// plugins. Posts from files win when ids collide.
+export function withContentAdapters(loader: Loader, adapters: ContentAdapter[]): Loader {
+  if (adapters.length === 0) return loader;
+
+  return {
+    ...loader,
+    load: async (context) => {
+      await loader.load(context);
+
+      for (const adapter of adapters) {
+        for (const { body = '', ...metadata } of await adapter({ logger: context.logger })) {
+          const data = normalizeMetadataKeys(metadata);
+          if (typeof data.slug !== 'string' || !data.slug.trim()) {
+            context.logger.warn(`skipping a generated post without a slug: ${JSON.stringify(metadata).slice(0, 80)}`);
+            continue;
+          }
+          // One bad item mustn't fail the whole sync.
+          let id: string;
+          try {
+            id = getEntryId('generated post', data);
+          } catch (error) {
+            context.logger.warn(`skipping ${(error as Error).message}`);
+            continue;
+          }
+          if (context.store.has(id)) {
+            context.logger.warn(`generated post "${id}" has the same id as a post file, using the file`);
+            continue;
+          }
+
+          context.store.set({
+            id,
+            data: await context.parseData({ id, data }),
+            body,
+            rendered: await context.renderMarkdown(body),
+            digest: context.generateDigest({ data, body }),
+          });
+        }
+      }
+    },
+  };
+}
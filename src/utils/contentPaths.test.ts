@@ -0,0 +1,45 @@
+import { describe, expect, test } from 'bun:test';
+import { normalizeName, slugifySegment, toEntryId } from './contentPaths';
+
+describe('slugifySegment', () => {
+  test.each([
+    ['Hello, World!', 'hello-world'],
+    ['Café', 'café'],
+    // Decomposed, as macOS hands out file names.
+    ['Cafe\u0301', 'café'],
+    ['Über Straße', 'über-straße'],
+    ['Привет мир', 'привет-мир'],
+    ['日本語の記事', '日本語の記事'],
+    ['हिन्दी लेख', 'हिन्दी-लेख'],
+    ['  C++ & Go 2 ', 'c-go-2'],
+  ])('%p → %p', (segment, expected) => {
+    expect(slugifySegment(segment)).toBe(expected);
+  });
+
+  test.each(['', '!!!', ' - ', '🙂'])('%p has no slug', (segment) => {
+    expect(() => slugifySegment(segment)).toThrow();
+  });
+});
+
+describe('toEntryId', () => {
+  test.each([
+    ['Nim/My Post.md', 'nim/my-post'],
+    ['Nim/2024-05-03-My Post.md', 'nim/my-post'],
+    ['Notes/Café.md', 'notes/café'],
+    ['Notes/Cafe\u0301.md', 'notes/café'],
+    ['Ryokō/index.md', 'ryokō'],
+  ])('%p → %p', (path, expected) => {
+    expect(toEntryId(path)).toBe(expected);
+  });
+
+  test('composed and decomposed file names get the same id', () => {
+    expect(toEntryId('Cafe\u0301/Re\u0301sume\u0301.md')).toBe(toEntryId('Café/Résumé.md'));
+  });
+});
+
+describe('normalizeName', () => {
+  test('composes decomposed characters', () => {
+    expect(normalizeName('cafe\u0301')).toBe('café');
+    expect(normalizeName('cafe\u0301')).toHaveLength(4);
+  });
+});
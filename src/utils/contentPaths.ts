@@ -0,0 +1,160 @@
+import { relative, resolve, sep } from 'path';
+import siteConfig from '../../site.config.mjs';
+import { normalizeMetadataKeys, readCommentMetadata } from './commentMetadata';
+import { createLogger } from './log.js';
+import { isSafeSitePath } from './outputPaths';
+import { BLOG_PATH } from './urls.js';
+
+const log = createLogger('contentPaths');
+
+// Shared by the content collection loader and the git metadata layer so both
+// derive the same entry id from a post's path.
+
+// Remote content is fetched into its `root`, which then works like any other.
+export const CONTENT_ROOTS: string[] = [
+  ...siteConfig.CONTENT_ROOTS,
+  ...siteConfig.REMOTE_CONTENT.map((source: { root: string }) => source.root),
+].map(normalizeRoot);
+
+function normalizeRoot(root: string): string {
+  return root.replace(/^\.\//, '').replace(/\/+$/, '') || '.';
+}
+
+// The build output directory, relative to the project root like the
+// content roots, and as an absolute path.
+const OUT_PATH = resolve(process.cwd(), siteConfig.OUT_DIR);
+const OUT_DIR = relative(process.cwd(), OUT_PATH).split(sep).join('/');
+
+// Build output and tooling directories, which must never be read as content
+// even when a content root contains them (e.g. a root of '.'). Otherwise a
+// build picks up the markdown it wrote into the output on the previous run.
+export const EXCLUDED_DIRS = [OUT_DIR, 'node_modules', '.git'];
+
+function containsPath(directory: string, path: string): boolean {
+  return path === directory || path.startsWith(`${directory}${sep}`);
+}
+
+for (const root of CONTENT_ROOTS) {
+  if (containsPath(resolve(process.cwd(), root), OUT_PATH)) {
+    log.warn(`content root "${root}" contains the build output directory ${OUT_DIR}; skipping it`);
+  }
+}
+
+// Glob patterns for the loaders, excluding build output and tooling.
+export function getContentPatterns(filePattern: string): string[] {
+  return [
+    ...CONTENT_ROOTS.map((root) => root === '.' ? filePattern : `${root}/${filePattern}`),
+    ...EXCLUDED_DIRS.map((directory) => `!${directory}/**`),
+  ];
+}
+
+export function isExcludedPath(projectPath: string): boolean {
+  return EXCLUDED_DIRS.some((directory) => projectPath === directory || projectPath.startsWith(`${directory}/`));
+}
+
+// Unicode text can reach us composed ("é") or decomposed ("e" + U+0301):
+// macOS file systems hand out decomposed file names, Linux keeps whatever
+// was written. Everything that turns names into ids or compares them goes
+// through NFC first, so a post gets the same id on every machine.
+export function normalizeName(name: string): string {
+  return name.normalize('NFC');
+}
+
+// A name as one URL path segment: lowercase letters and digits of any
+// script (so "Café" is "café", not "caf"), everything else collapsed to
+// dashes. Names without a single letter or digit have no slug and fail.
+export function slugifySegment(segment: string): string {
+  const slug = normalizeName(segment)
+    .toLowerCase()
+    .replace(/[^\p{L}\p{M}\p{N}]+/gu, '-')
+    .replace(/^-+|-+$/g, '');
+  if (!slug) throw new Error(`"${segment}" has no letters or digits to make a URL slug of`);
+  return slug;
+}
+
+// Jekyll-style `2024-05-03-my-post.md` file names carry the post's date,
+// which is not part of its title or URL.
+const DATED_FILE_NAME = /^(\d{4}-\d{2}-\d{2})-(.+)$/;
+
+export function splitDatedFileName(baseName: string): { date?: string; name: string } {
+  const match = baseName.match(DATED_FILE_NAME);
+  return match ? { date: match[1], name: match[2] } : { name: baseName };
+}
+
+export function toEntryId(relativePath: string): string {
+  const parts = relativePath.split('/');
+  const fileName = parts.pop() || '';
+  const baseName = splitDatedFileName(fileName.replace(/\.md$/, '')).name;
+  const slugParts = parts.map(slugifySegment);
+  slugParts.push(slugifySegment(baseName));
+  // Page bundles (`post/index.md`) are addressed by their directory.
+  return slugParts.join('/').replace(/\/index$/, '');
+}
+
+// The id the blog collection assigns to an entry: an explicit `slug` in the
+// metadata wins, otherwise it is derived from the path below its content root.
+export function getEntryId(relativePath: string, data: Record<string, unknown>): string {
+  if (typeof data.slug === 'string' && data.slug.trim()) {
+    const slug = normalizeName(data.slug.trim()).replace(/^\/+|\/+$/g, '');
+    // The id becomes the post's directory in the output.
+    if (!isSafeSitePath(slug)) throw new Error(`${relativePath}: slug "${data.slug}" may not contain . or .. segments`);
+    return slug;
+  }
+  return toEntryId(relativePath);
+}
+
+// Read a single-line metadata value straight from a markdown source, for
+// code that runs outside the content layer. YAML frontmatter wins over a
+// comment metadata block, as it does for the collection itself.
+function readMetadataValue(source: string, key: string): string | undefined {
+  const frontmatter = source.match(/^---\r?\n([\s\S]*?)\r?\n---/);
+  for (const [, name, value] of frontmatter?.[1].matchAll(/^([A-Za-z][\w-]*):[ \t]*(.+?)\s*$/gm) ?? []) {
+    if (key in normalizeMetadataKeys({ [name]: true })) return value.replace(/^(["'])(.*)\1$/, '$2');
+  }
+
+  const commentValue = readCommentMetadata(source)[key];
+  return typeof commentValue === 'string' ? commentValue : undefined;
+}
+
+export function readSlug(source: string): string | undefined {
+  return readMetadataValue(source, 'slug');
+}
+
+// A post's `url`, validated as toOutputPath does.
+export function readOutputPath(source: string): string | undefined {
+  const url = readMetadataValue(source, 'url');
+  return url ? toOutputPath(url) : undefined;
+}
+
+// A post's `url` (or `OutputPath:`) as the site path it is published at:
+// `/2019/05/hello/` for a directory, `/old/hello.html` for a single file.
+// Paths without an extension, and index.html files, are directories.
+// Undefined when the value isn't a root-relative path, has . or .. segments,
+// or names a file other than an .html page.
+export function toOutputPath(url: string): string | undefined {
+  const path = normalizeName(url.trim());
+  if (!path.startsWith('/') || path.startsWith('//') || /[?#]/.test(path) || !isSafeSitePath(path)) return undefined;
+
+  const lastSegment = path.slice(path.lastIndexOf('/') + 1);
+  if (!lastSegment.includes('.')) return path.endsWith('/') ? path : `${path}/`;
+  if (lastSegment === 'index.html') return path.slice(0, -lastSegment.length);
+  return lastSegment.endsWith('.html') ? path : undefined;
+}
+
+// Site path of a post's page, below BASE_PATH: its pinned `url`, or its
+// directory below the blog.
+export function getPostPath(id: string, url?: string): string {
+  return url ?? `/${BLOG_PATH}/${id}/`;
+}
+
+// Split a project-relative path into the content root it lives under and the
+// path relative to that root. The most specific root wins when roots nest.
+export function splitContentPath(path: string): { root: string; relativePath: string } | undefined {
+  const root = CONTENT_ROOTS
+    .filter((candidate) => candidate === '.' || path.startsWith(`${candidate}/`))
+    .sort((a, b) => b.length - a.length)[0];
+
+  if (!root) return undefined;
+
+  return { root, relativePath: root === '.' ? path : path.slice(root.length + 1) };
+}
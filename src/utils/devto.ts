@@ -0,0 +1,37 @@
+import type { CollectionEntry } from 'astro:content';
+import siteConfig from '../../site.config.mjs';
+import { getPostTitle, getPostURL } from './content';
+
+// dev.to accepts at most four tags, lowercase and alphanumeric only.
+function toDevtoTags(tags: string[]): string[] {
+  return tags
+    .map(tag => tag.toLowerCase().replace(/[^a-z0-9]/g, ''))
+    .filter(Boolean)
+    .slice(0, 4);
+}
+
+function quote(value: string): string {
+  return JSON.stringify(value);
+}
+
+// Render a post as dev.to-flavored markdown: dev.to front matter with a
+// canonical_url back to this site, and root-relative links made absolute so
+// they keep working once pasted over there.
+export function toDevtoMarkdown(entry: CollectionEntry<'blog'>): string {
+  const siteURL = siteConfig.SITE_URL.replace(/\/+$/, '');
+  const { description, tags } = entry.data;
+
+  const frontmatter = [
+    `title: ${quote(getPostTitle(entry))}`,
+    'published: false',
+    description && `description: ${quote(description)}`,
+    tags.length > 0 && `tags: ${toDevtoTags(tags).join(', ')}`,
+    `canonical_url: ${siteURL}${getPostURL(entry)}`,
+  ].filter(Boolean);
+
+  const body = (entry.body ?? '')
+    .replace(/^(?:---\r?\n[\s\S]*?\r?\n---\r?\n)?\s*(?:<!--[ \t]*\r?\n[\s\S]*?-->\r?\n)?/, '')
+    .replace(/(\]\(|\b(?:src|href)=["'])\/(?!\/)/g, `$1${siteURL}/`);
+
+  return `---\n${frontmatter.join('\n')}\n---\n\n${body.trim()}\n`;
+}
@@ -0,0 +1,29 @@
+import type { CollectionEntry } from 'astro:content';
+import { renderPostBody, withAbsoluteURLs } from './postHTML';
+import siteConfig from '../../site.config.mjs';
+
+function escapeXml(value: string): string {
+  return value
+    .replace(/&/g, '&amp;')
+    .replace(/</g, '&lt;')
+    .replace(/>/g, '&gt;');
+}
+
+// Extra <channel> elements shared by every feed, driven by site config.
+export function getChannelCustomData(): string {
+  const copyright = siteConfig.FEED_COPYRIGHT || siteConfig.COPYRIGHT;
+
+  return [
+    siteConfig.FEED_LANGUAGE && `<language>${escapeXml(siteConfig.FEED_LANGUAGE)}</language>`,
+    copyright && `<copyright>${escapeXml(copyright)}</copyright>`,
+    siteConfig.FEED_CATEGORY && `<category>${escapeXml(siteConfig.FEED_CATEGORY)}</category>`,
+    siteConfig.FEED_TTL && `<ttl>${siteConfig.FEED_TTL}</ttl>`,
+  ].filter(Boolean).join('');
+}
+
+// Full rendered post for an item's content:encoded, when FEED_FULL_CONTENT
+// is on, with its images resolved and its URLs absolute.
+export async function getItemContent(entry: CollectionEntry<'blog'>): Promise<string | undefined> {
+  if (!siteConfig.FEED_FULL_CONTENT) return undefined;
+  return withAbsoluteURLs(await renderPostBody(entry));
+}
@@ -0,0 +1,21 @@
+const OPTION = /([A-Za-z_][\w-]*)(?:\s*=\s*("[^"]*"|'[^']*'|\[[^\]]*\]|[^\s}]+))?/g;
+
+// Parse the info string after a fence's language, e.g.
+// `title="main.go" {hl_lines=[3,5-7], linenostart=10}`, into key/value
+// pairs. Bare keys are returned as true.
+export function parseFenceMeta(meta) {
+  const options = {};
+  if (!meta) return options;
+
+  for (const [, key, rawValue] of meta.matchAll(OPTION)) {
+    if (rawValue === undefined) {
+      options[key] = true;
+    } else if (/^(["']).*\1$/.test(rawValue)) {
+      options[key] = rawValue.slice(1, -1);
+    } else {
+      options[key] = rawValue.replace(/,$/, '');
+    }
+  }
+
+  return options;
+}
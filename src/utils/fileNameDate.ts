@@ -0,0 +1,20 @@
+import { basename } from 'path';
+import type { Loader } from 'astro/loaders';
+import { splitDatedFileName } from './contentPaths';
+
+// Use the date in a `2024-05-03-my-post.md` file name for posts whose
+// metadata doesn't set one, as Jekyll does.
+export function withFileNameDate(loader: Loader): Loader {
+  return {
+    ...loader,
+    load: (context) => loader.load({
+      ...context,
+      parseData: (props) => {
+        const { date } = splitDatedFileName(basename(props.filePath ?? '', '.md'));
+        if (!date || props.data.date !== undefined) return context.parseData(props);
+
+        return context.parseData({ ...props, data: { ...props.data, date } });
+      },
+    }),
+  };
+}
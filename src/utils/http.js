@@ -0,0 +1,109 @@
+import { createHash } from 'crypto';
+import { readFileSync } from 'fs';
+import { join } from 'path';
+import siteConfig from '../../site.config.mjs';
+import { createLogger } from './log.js';
+import { writeFileAtomic } from './writeFileAtomic.js';
+
+const log = createLogger('http');
+
+const CACHE_DIR = join(process.cwd(), 'node_modules/.cache/krea.to/http');
+const RETRY_BASE_DELAY = 1000;
+const MAX_RETRY_DELAY = 30 * 1000;
+
+// OFFLINE in site.config.mjs, or OFFLINE=1 in the environment for a single
+// build (`OFFLINE=1 bun run build`).
+export const OFFLINE = siteConfig.OFFLINE || process.env.OFFLINE === '1';
+
+// Thrown when a request can't be made (offline, or cacheOnly) and nothing
+// is cached for it.
+export class OfflineError extends Error {}
+
+function getCachePath(url, init) {
+  // Headers stay out of the key, so a rotated token doesn't drop the cache.
+  const key = `${init.method ?? 'GET'} ${url}\n${init.body ?? ''}`;
+  return join(CACHE_DIR, `${createHash('sha256').update(key).digest('hex')}.json`);
+}
+
+function readCache(path) {
+  try {
+    return JSON.parse(readFileSync(path, 'utf-8'));
+  } catch {
+    return undefined;
+  }
+}
+
+function toResponse(entry) {
+  return new Response(entry.body, { status: entry.status, headers: { 'Content-Type': entry.contentType ?? '' } });
+}
+
+function isRetryable(status) {
+  return status === 408 || status === 429 || status >= 500;
+}
+
+function sleep(milliseconds) {
+  return new Promise(resolve => setTimeout(resolve, milliseconds));
+}
+
+// fetch() for build steps that talk to other services:
+//
+// - Each attempt times out after `timeout` milliseconds.
+// - Network errors, timeouts, 429s and 5xx responses are retried `retries`
+//   times with exponential backoff, honoring Retry-After. Only GET and HEAD
+//   are retried by default; pass `retries` for requests that are safe to
+//   repeat (read-only queries, requests with an idempotency key).
+// - With `cacheTTL` (minutes), successful responses are cached and reused
+//   while fresh. A stale copy is served when the service can't be reached.
+// - Offline (OFFLINE, or `cacheOnly` for this call), nothing is sent: the
+//   cached copy is returned whatever its age, or OfflineError thrown.
+export async function request(url, init = {}, options = {}) {
+  const method = init.method ?? 'GET';
+  const {
+    retries = method === 'GET' || method === 'HEAD' ? 3 : 0,
+    timeout = 15 * 1000,
+    cacheTTL,
+    cacheOnly = false,
+  } = options;
+
+  const cachePath = cacheTTL === undefined ? undefined : getCachePath(String(url), init);
+  const cached = cachePath && readCache(cachePath);
+
+  if (OFFLINE || cacheOnly) {
+    if (cached) return toResponse(cached);
+    throw new OfflineError(`${OFFLINE ? 'offline' : 'not fetching'} and nothing cached for ${url}`);
+  }
+  if (cached && Date.now() - cached.fetchedAt < cacheTTL * 60 * 1000) return toResponse(cached);
+
+  let lastError;
+  for (let attempt = 0; attempt <= retries; attempt++) {
+    let delay = Math.min(RETRY_BASE_DELAY * 2 ** attempt, MAX_RETRY_DELAY);
+    try {
+      const response = await fetch(url, { ...init, signal: AbortSignal.timeout(timeout) });
+      lastError = new Error(`${method} ${url} returned ${response.status}`);
+      if (!isRetryable(response.status) || attempt === retries) {
+        if (!response.ok && cached) break;
+        if (cachePath && response.ok) {
+          const body = await response.text();
+          const entry = { fetchedAt: Date.now(), status: response.status, contentType: response.headers.get('Content-Type'), body };
+          writeFileAtomic(cachePath, JSON.stringify(entry));
+          return toResponse(entry);
+        }
+        return response;
+      }
+      const retryAfter = Number(response.headers.get('Retry-After'));
+      if (retryAfter > 0) delay = Math.min(retryAfter * 1000, MAX_RETRY_DELAY);
+    } catch (error) {
+      lastError = error;
+    }
+    if (attempt < retries) {
+      log.debug(`${lastError.message}, retrying in ${delay / 1000}s`, String(url));
+      await sleep(delay);
+    }
+  }
+
+  if (cached) {
+    log.warn(`using a cached response from ${new Date(cached.fetchedAt).toISOString()}: ${lastError?.message ?? 'request failed'}`, String(url));
+    return toResponse(cached);
+  }
+  throw lastError;
+}
@@ -0,0 +1,52 @@
+import { appendFileSync, mkdirSync, writeFileSync } from 'fs';
+import { dirname, isAbsolute, relative } from 'path';
+import siteConfig from '../../site.config.mjs';
+
+// Set on globalThis rather than in a module variable: the config and the
+// bundles pages are prerendered from each load their own copy of this
+// module in the same process, and each copy would start the file over.
+const LOG_FILE_STARTED = Symbol.for('krea.to.logFileStarted');
+
+function writeLogFile(level, line) {
+  if (!siteConfig.LOG_FILE) return;
+  // Each build (or dev server) starts the file over; every line after that
+  // is appended, so lines from pages rendered in parallel never overwrite
+  // each other.
+  if (!globalThis[LOG_FILE_STARTED]) {
+    mkdirSync(dirname(siteConfig.LOG_FILE), { recursive: true });
+    writeFileSync(siteConfig.LOG_FILE, '');
+    globalThis[LOG_FILE_STARTED] = true;
+  }
+  appendFileSync(siteConfig.LOG_FILE, `${new Date().toISOString()} ${level.padEnd(5)} ${line}\n`);
+}
+
+function format(name, message, file) {
+  const source = file ? `${isAbsolute(file) ? relative(process.cwd(), file) : file}: ` : '';
+  return `[${name}] ${source}${message}`;
+}
+
+// Build logging for plugins and utilities. Every line names its component
+// and, when given, the source file it is about, so messages from pages
+// rendered in parallel can still be told apart:
+//
+//   [wikiLink] src/content/blog/Nim/ffi.md: no post matches [[Nim/Missing]]
+//
+// Debug lines go to the console when DEBUG is on. With LOG_FILE set they
+// are written there instead, whatever DEBUG says, keeping the console to
+// warnings. Warnings go to both. Only these loggers write to LOG_FILE;
+// Astro's own output and the logger it hands integrations stay on the
+// console.
+export function createLogger(name) {
+  return {
+    debug(message, file) {
+      const line = format(name, message, file);
+      writeLogFile('debug', line);
+      if (siteConfig.DEBUG && !siteConfig.LOG_FILE) console.log(line);
+    },
+    warn(message, file) {
+      const line = format(name, message, file);
+      writeLogFile('warn', line);
+      console.warn(line);
+    },
+  };
+}
@@ -0,0 +1,37 @@
+import { readingTimePlugin } from '../plugins/readingTimePlugin.js';
+import { codeWrapperPlugin } from '../plugins/codeWrapperPlugin.js';
+import { ansiPlugin } from '../plugins/ansiPlugin.js';
+import { asciinemaPlugin } from '../plugins/asciinemaPlugin.js';
+import { diagramPlugin } from '../plugins/diagramPlugin.js';
+import { csvTablePlugin } from '../plugins/csvTablePlugin.js';
+import { chartPlugin } from '../plugins/chartPlugin.js';
+import { mathPlugin } from '../plugins/mathPlugin.js';
+import { wikiLinkPlugin } from '../plugins/wikiLinkPlugin.js';
+import { titleHeadingPlugin } from '../plugins/titleHeadingPlugin.js';
+import { fenceOptionsTransformer } from '../plugins/fenceOptionsTransformer.js';
+import siteConfig from '../../site.config.mjs';
+
+// The site's markdown pipeline, with the remark and rehype plugins of the
+// loaded PLUGINS after the built-in ones. Shared by astro.config.mjs and
+// scripts/render.ts, which mustn't import the whole Astro config (it syncs
+// remote content and sets up integrations) to render one file.
+export function getMarkdownConfig(plugins) {
+  return {
+    // GitHub-flavored markdown, which brings `[^1]` footnotes along with
+    // tables and task lists. The footnote section gets a visually hidden
+    // heading and each back-reference an aria-label naming its target.
+    gfm: true,
+    shikiConfig: {
+      theme: siteConfig.CODE_THEME,
+      transformers: [fenceOptionsTransformer()],
+    },
+    remarkRehype: {
+      footnoteLabel: 'Footnotes',
+      footnoteLabelProperties: { className: ['sr-only'] },
+      footnoteBackLabel: (referenceIndex, rereferenceIndex) =>
+        `Back to reference ${referenceIndex + 1}${rereferenceIndex > 1 ? `-${rereferenceIndex}` : ''}`,
+    },
+    remarkPlugins: [readingTimePlugin, titleHeadingPlugin, wikiLinkPlugin, ansiPlugin, asciinemaPlugin, diagramPlugin, csvTablePlugin, chartPlugin, mathPlugin, ...plugins.remarkPlugins],
+    rehypePlugins: [codeWrapperPlugin, ...plugins.rehypePlugins],
+  };
+}
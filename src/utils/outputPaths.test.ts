@@ -0,0 +1,71 @@
+import { describe, expect, test } from 'bun:test';
+import { mkdirSync, mkdtempSync, symlinkSync } from 'fs';
+import { tmpdir } from 'os';
+import { join } from 'path';
+import { isInside, isSafeSitePath, joinInside } from './outputPaths';
+
+const root = mkdtempSync(join(tmpdir(), 'krea-output-'));
+const output = join(root, 'dist');
+mkdirSync(join(output, 'blog'), { recursive: true });
+mkdirSync(join(root, 'secret'));
+symlinkSync(join(root, 'secret'), join(output, 'escape'));
+symlinkSync(join(output, 'blog'), join(output, 'posts'));
+
+describe('isInside', () => {
+  test.each([
+    ['blog/post/index.html', true],
+    ['', true],
+    ['.', true],
+    ['blog/../index.html', true],
+    ['..', false],
+    ['../secret', false],
+    ['blog/../../secret', false],
+    ['/etc/passwd', false],
+    [join(output, 'blog'), true],
+    [join(root, 'dist-other'), false],
+    // A symlink in the output that points out of it.
+    ['escape', false],
+    ['escape/file.png', false],
+    // One that points elsewhere inside it.
+    ['posts/file.png', true],
+    // Backslashes are file name characters on POSIX, not separators.
+    ['..\\secret', true],
+    // Percent-escapes are file name characters too.
+    ['%2e%2e/secret', true],
+  ])('%p → %p', (path, expected) => {
+    expect(isInside(output, path)).toBe(expected);
+  });
+});
+
+describe('joinInside', () => {
+  test('joins paths inside the root', () => {
+    expect(joinInside(output, 'manifest.json')).toBe(join(output, 'manifest.json'));
+  });
+
+  test.each(['../manifest.json', '../../etc/passwd', 'blog/../../x', 'escape/manifest.json'])('rejects %p', (path) => {
+    expect(() => joinInside(output, path)).toThrow();
+  });
+});
+
+describe('isSafeSitePath', () => {
+  test.each([
+    ['blog/my-post', true],
+    ['old/path.html', true],
+    ['v1.2/notes', true],
+    ['..foo/bar', true],
+    ['100%', true],
+    ['..', false],
+    ['.', false],
+    ['blog/../../etc', false],
+    ['blog/./post', false],
+    ['%2e%2e/etc', false],
+    ['blog/%2E%2E/%2e%2e', false],
+    ['%2e', false],
+    ['..\\etc', false],
+    ['blog\\post', false],
+    ['blog/post\0', false],
+    ['blog/%00', false],
+  ])('%p → %p', (path, expected) => {
+    expect(isSafeSitePath(path)).toBe(expected);
+  });
+});
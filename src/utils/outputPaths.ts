@@ -0,0 +1,47 @@
+import { realpathSync } from 'fs';
+import { basename, dirname, isAbsolute, join, relative, resolve, sep } from 'path';
+
+// Output paths are put together from post metadata (slugs, aliases), from
+// markup (image sources) and from link targets, and any of those can hold a
+// `..`. Whatever writes into the build output, or links a file from it,
+// checks the final path here, so a hostile or mistyped value fails or is
+// skipped instead of landing outside the output directory.
+
+// `path` with symlinks resolved as far as it exists, so a link inside a
+// directory that points out of it doesn't count as inside.
+function realPath(path: string): string {
+  try {
+    return realpathSync(path);
+  } catch {
+    const parent = dirname(path);
+    return parent === path ? path : join(realPath(parent), basename(path));
+  }
+}
+
+// Whether `path` (absolute, or relative to `root`) is inside `root`.
+export function isInside(root: string, path: string): boolean {
+  const rel = relative(realPath(resolve(root)), realPath(resolve(root, path)));
+  return !isAbsolute(rel) && rel.split(sep)[0] !== '..';
+}
+
+// `path` joined onto `root`, or an error when the result is outside it.
+export function joinInside(root: string, path: string): string {
+  const joined = join(root, path);
+  if (!isInside(root, joined)) throw new Error(`${path} is outside ${root}`);
+  return joined;
+}
+
+// Whether a site path like a post id or an alias names a place below the
+// site root: no `.` or `..` segments (percent-encoded or not), backslashes
+// or NUL bytes, which file systems or the router would resolve to
+// somewhere else.
+export function isSafeSitePath(path: string): boolean {
+  let decoded = path;
+  try {
+    decoded = decodeURIComponent(path);
+  } catch {
+    // A stray `%` that isn't an escape.
+  }
+  return [path, decoded].every(candidate => !/[\\\0]/.test(candidate)
+    && candidate.split('/').every(segment => segment !== '.' && segment !== '..'));
+}
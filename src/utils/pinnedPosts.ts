@@ -0,0 +1,68 @@
+import { getCollection } from 'astro:content';
+import type { CollectionEntry } from 'astro:content';
+import { getPostURL } from './content';
+import { getStubRedirects } from './redirects';
+import { TAXONOMIES, getAllTerms } from './taxonomies';
+import { blogURL, withoutBase } from './urls.js';
+import siteConfig from '../../site.config.mjs';
+
+// A site path (below BASE_PATH) as a key for comparing them, with one
+// leading slash and no index.html or trailing slash.
+function toKey(path: string): string {
+  return `/${path.replace(/\/index\.html$/, '').replace(/^\/+|\/+$/g, '')}`;
+}
+
+// The pages published below a post's blog path whatever its own path is,
+// as far as the options that turn them on are set.
+function getSubPages(post: CollectionEntry<'blog'>): string[] {
+  const debug = siteConfig.TEMPLATE_DEBUG;
+  return [
+    siteConfig.PRINT_PAGES && `${post.id}/print/`,
+    siteConfig.FRAGMENTS && `${post.id}/fragment.html`,
+    siteConfig.OG_CARDS && !post.data.image && `${post.id}/og.png`,
+    (debug === true || (Array.isArray(debug) && debug.includes(post.id))) && `${post.id}/data.json`,
+    siteConfig.DEVTO_EXPORT && `${post.id}.devto.md`,
+  ].filter((page): page is string => Boolean(page));
+}
+
+// Every page path the site generates apart from the pinned posts, with
+// what it belongs to.
+async function getClaimedPaths(): Promise<Map<string, string>> {
+  const claims = new Map<string, string>();
+  const claim = (path: string, owner: string) => {
+    if (!claims.has(toKey(path))) claims.set(toKey(path), owner);
+  };
+
+  claim('/', 'the landing page');
+  claim(withoutBase(blogURL()), 'the blog index');
+  for (const post of await getCollection('blog')) {
+    if (!post.data.url) claim(withoutBase(getPostURL(post)), `post ${post.id}`);
+    for (const alias of post.data.aliases) claim(alias, `an alias of post ${post.id}`);
+    for (const page of getSubPages(post)) claim(withoutBase(blogURL(page)), `a page of post ${post.id}`);
+  }
+  for (const { from } of getStubRedirects()) claim(from, `a redirect in ${siteConfig.REDIRECTS_FILE}`);
+  for (const [taxonomy, terms] of Object.entries(await getAllTerms())) {
+    const { indexPath } = TAXONOMIES[taxonomy];
+    if (indexPath) claim(withoutBase(blogURL(indexPath)), `the ${taxonomy} index`);
+    for (const term of terms) claim(withoutBase(term.url), `the ${taxonomy} page for ${term.name}`);
+  }
+  return claims;
+}
+
+// Posts with a `url`, which are published at exactly that path instead of
+// below the blog. Each path is checked against the other pinned posts and
+// every page the site generates otherwise, so a pinned post can never
+// overwrite another page (or be overwritten by one).
+export async function getPinnedPosts(): Promise<CollectionEntry<'blog'>[]> {
+  const pinned = (await getCollection('blog')).filter(post => post.data.url);
+  if (pinned.length === 0) return pinned;
+
+  const claims = await getClaimedPaths();
+  for (const post of pinned) {
+    const key = toKey(post.data.url!);
+    const owner = claims.get(key);
+    if (owner) throw new Error(`post ${post.id} has url ${post.data.url}, which is already used by ${owner}`);
+    claims.set(key, `post ${post.id}`);
+  }
+  return pinned;
+}
@@ -0,0 +1,29 @@
+import { pathToFileURL } from 'url';
+import { resolve } from 'path';
+
+// Load third-party site plugins listed in PLUGINS. A plugin is any module
+// (a local file or an installed package) that exports any of:
+//
+//   export const remarkPlugins = [...];   // markdown transforms
+//   export const rehypePlugins = [...];   // HTML transforms
+//   export const integrations = [...];    // Astro integrations (pages, build hooks, deploys)
+//   export const contentAdapters = [...]; // functions returning generated posts
+//                                         // (see src/utils/contentAdapters.ts)
+//
+// Their entries are appended after the built-in ones.
+export async function loadPlugins(specifiers) {
+  const plugins = { remarkPlugins: [], rehypePlugins: [], integrations: [], contentAdapters: [] };
+
+  for (const specifier of specifiers) {
+    const url = specifier.startsWith('.') || specifier.startsWith('/')
+      ? pathToFileURL(resolve(process.cwd(), specifier)).href
+      : specifier;
+    const plugin = await import(url);
+
+    for (const key of Object.keys(plugins)) {
+      if (plugin[key]) plugins[key].push(...plugin[key]);
+    }
+  }
+
+  return plugins;
+}
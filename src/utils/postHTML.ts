@@ -0,0 +1,24 @@
+import { experimental_AstroContainer as AstroContainer } from 'astro/container';
+import { render } from 'astro:content';
+import type { CollectionEntry } from 'astro:content';
+import siteConfig from '../../site.config.mjs';
+
+// A post's body as HTML outside of a page, for feeds and fragments. The
+// collection's `rendered.html` can't be used for that: local images in it
+// are still placeholders that only render() swaps for the built assets.
+
+let container: ReturnType<typeof AstroContainer.create> | undefined;
+
+export async function renderPostBody(entry: CollectionEntry<'blog'>): Promise<string> {
+  container ??= AstroContainer.create();
+  const { Content } = await render(entry);
+  return (await container).renderToString(Content);
+}
+
+// `html` with root-relative URLs made absolute, for HTML read somewhere
+// other than the site: feed readers resolve links against the feed, and
+// an embedded fragment against the page embedding it.
+export function withAbsoluteURLs(html: string): string {
+  const siteURL = siteConfig.SITE_URL.replace(/\/+$/, '');
+  return html.replace(/\b(src|href)="\/(?!\/)/g, `$1="${siteURL}/`);
+}
@@ -1,54 +1,151 @@
 import { execSync } from 'child_process';
-import { readdirSync, statSync } from 'fs';
+import { existsSync, readFileSync, readdirSync, realpathSync, statSync } from 'fs';
 import fs from 'fs';
 import * as git from 'isomorphic-git';
-import { join, relative, sep } from 'path';
-import siteConfig from '../../site.config.mjs';
+import { dirname, join, relative, sep } from 'path';
+import { CONTENT_ROOTS, getEntryId, getPostPath, isExcludedPath, normalizeName, readOutputPath, readSlug, splitContentPath, splitDatedFileName, toEntryId } from './contentPaths';
+import { writeFileAtomic } from './writeFileAtomic.js';
+import { createLogger } from './log.js';
+import { withoutBase } from './urls.js';
+
+const log = createLogger('postMetadata');
 
 interface PostComputedMetadata {
   title: string;
+  // Source path below the post's content root.
+  relativePath: string;
+  filePath: string;
   originalDirectory?: string;
+  // Site path of the post's page, below BASE_PATH.
+  path: string;
   commitHash?: string;
   commitDate?: string;
   commitAuthor?: string;
   commitURL?: string;
+  editURL?: string;
+  lastModified?: string;
 }
 
-const BLOG_ROOT = join(process.cwd(), 'src/content/blog');
+interface CommitInfo {
+  hash: string;
+  date: string;
+  author: string;
+}
+
+// A git repository containing content. Submodules get their own entry so
+// commit info comes from the submodule, not from the superproject's gitlink.
+interface Repository {
+  dir: string;
+  gitdir: string;
+  url?: string;
+  branch?: string;
+  commits?: Record<string, CommitInfo>;
+}
+
+// Path -> last commit results per repository, keyed by HEAD so builds on an
+// unchanged repository skip the history walk entirely.
+interface CommitCacheEntry {
+  head: string;
+  commits: Record<string, CommitInfo>;
+}
+
+const COMMIT_CACHE_PATH = join(process.cwd(), 'node_modules/.cache/krea.to/commits.json');
 
 let cache: Map<string, PostComputedMetadata> | null = null;
-const REPOSITORY_URL = await resolveRepositoryURL();
+let commitCache: Record<string, CommitCacheEntry> | null = null;
+const repositoriesByDirectory = new Map<string, Repository | null>();
+await discoverRepositories();
+
+function findRepository(directory: string): Repository | null {
+  const known = repositoriesByDirectory.get(directory);
+  if (known !== undefined) return known;
+
+  let repository: Repository | null = null;
+  try {
+    const output = execSync('git rev-parse --show-toplevel --absolute-git-dir', {
+      cwd: directory,
+      encoding: 'utf-8',
+      stdio: ['ignore', 'pipe', 'ignore'],
+    }).trim();
+    const [dir, gitdir] = output.split('\n');
+    if (dir && gitdir) {
+      repository = Array.from(repositoriesByDirectory.values()).find((known) => known?.gitdir === gitdir) ?? { dir, gitdir };
+    }
+  } catch {
+    repository = null;
+  }
+
+  repositoriesByDirectory.set(directory, repository);
+  return repository;
+}
+
+function readCommitCache(): Record<string, CommitCacheEntry> {
+  if (!commitCache) {
+    try {
+      commitCache = JSON.parse(readFileSync(COMMIT_CACHE_PATH, 'utf-8'));
+    } catch {
+      commitCache = {};
+    }
+  }
+  return commitCache!;
+}
 
-function slugifySegment(segment: string): string {
-  return segment
-    .toLowerCase()
-    .replace(/[^a-z0-9]+/g, '-')
-    .replace(/^-+|-+$/g, '');
+function writeCommitCache(): void {
+  try {
+    writeFileAtomic(COMMIT_CACHE_PATH, JSON.stringify(commitCache));
+  } catch {
+    // The cache is only an optimization; a failed write just means a slower next build.
+  }
 }
 
-function toEntryId(relativePath: string): string {
-  const parts = relativePath.split('/');
-  const fileName = parts.pop() || '';
-  const baseName = fileName.replace(/\.md$/, '');
-  const slugParts = parts.map(slugifySegment);
-  slugParts.push(slugifySegment(baseName));
-  return slugParts.join('/');
+// Walk the history once and record the most recent commit for every path it
+// touched, instead of running `git log` per file. git picks up the repository's
+// commit-graph file on its own when one exists.
+function indexCommits(repository: Repository): Record<string, CommitInfo> {
+  const output = execSync('git -c core.quotePath=false log --format=%x00%H%n%ai%n%an --name-only', {
+    cwd: repository.dir,
+    encoding: 'utf-8',
+    maxBuffer: 256 * 1024 * 1024,
+  });
+
+  const commits: Record<string, CommitInfo> = {};
+  for (const record of output.split('\0').slice(1)) {
+    const [hash, date, author, ...paths] = record.split('\n');
+    for (const path of paths) {
+      if (path && !(path in commits)) {
+        commits[path] = { hash, date, author };
+      }
+    }
+  }
+
+  return commits;
 }
 
-function queryGitInfo(repoRelativePath: string): { hash: string; date: string; author: string } | null {
-  const command = `git log -1 --format=%H%n%ai%n%an -- "${repoRelativePath}"`;
-  const output = execSync(command, { encoding: 'utf-8' }).trim();
+function getCommitIndex(repository: Repository): Record<string, CommitInfo> {
+  if (repository.commits) return repository.commits;
+
+  const head = execSync('git rev-parse HEAD', { cwd: repository.dir, encoding: 'utf-8' }).trim();
+  const cached = readCommitCache()[repository.gitdir];
 
-  if (siteConfig.DEBUG) {
-    console.log(`[postMetadata] git query path=${repoRelativePath} hasOutput=${output.length > 0}`);
+  if (cached?.head === head) {
+    repository.commits = cached.commits;
+  } else {
+    repository.commits = indexCommits(repository);
+    readCommitCache()[repository.gitdir] = { head, commits: repository.commits };
+    writeCommitCache();
   }
 
-  if (!output) return null;
+  log.debug(`commit index head=${head.slice(0, 7)} cached=${cached?.head === head}`, repository.dir);
 
-  const [hash, date, author] = output.split('\n');
-  if (!hash || !date || !author) return null;
+  return repository.commits;
+}
+
+function queryGitInfo(repository: Repository, repoRelativePath: string): CommitInfo | null {
+  const gitInfo = getCommitIndex(repository)[repoRelativePath] ?? null;
 
-  return { hash, date, author };
+  log.debug(`git query found=${gitInfo !== null}`, repoRelativePath);
+
+  return gitInfo;
 }
 
 function normalizeRemoteURL(remoteURL: string): string | undefined {
@@ -77,18 +174,19 @@ function normalizeRemoteURL(remoteURL: string): string | undefined {
   return undefined;
 }
 
-async function resolveRepositoryURL(): Promise<string | undefined> {
+async function resolveRepositoryURL(repository: Repository): Promise<string | undefined> {
   try {
-    const dir = process.cwd();
+    const { dir, gitdir } = repository;
     let remoteName: string | undefined;
 
-    const currentBranch = await git.currentBranch({ fs, dir, fullname: false });
+    const currentBranch = await git.currentBranch({ fs, dir, gitdir, fullname: false });
+    repository.branch = currentBranch || undefined;
     if (currentBranch) {
-      const upstream = await git.getConfig({ fs, dir, path: `branch.${currentBranch}.remote` });
+      const upstream = await git.getConfig({ fs, dir, gitdir, path: `branch.${currentBranch}.remote` });
       if (upstream) remoteName = upstream;
     }
 
-    const remotes = await git.listRemotes({ fs, dir });
+    const remotes = await git.listRemotes({ fs, dir, gitdir });
     const names = remotes.map((r) => r.remote);
 
     if (!remoteName || !names.includes(remoteName)) {
@@ -104,21 +202,31 @@ async function resolveRepositoryURL(): Promise<string | undefined> {
   }
 }
 
-function readGitInfo(repoRelativePaths: string[]): Omit<PostComputedMetadata, 'title' | 'originalDirectory'> {
+// Resolve every repository that holds content up front, since remote lookup
+// is async while the metadata cache is built synchronously.
+async function discoverRepositories(): Promise<void> {
+  const resolved = new Set<Repository>();
+  for (const filePath of listContentFiles()) {
+    const repository = findRepository(dirname(filePath));
+    if (!repository || resolved.has(repository)) continue;
+    resolved.add(repository);
+    repository.url = await resolveRepositoryURL(repository);
+  }
+}
+
+function readGitInfo(repository: Repository, repoRelativePaths: string[]): Omit<PostComputedMetadata, 'title' | 'originalDirectory' | 'editURL' | 'lastModified'> {
   try {
-    let gitInfo: { hash: string; date: string; author: string } | null = null;
+    let gitInfo: CommitInfo | null = null;
     for (const path of repoRelativePaths) {
-      gitInfo = queryGitInfo(path);
+      gitInfo = queryGitInfo(repository, path);
       if (gitInfo) break;
     }
 
     if (!gitInfo) return {};
 
-    const repoURL = REPOSITORY_URL;
+    const repoURL = repository.url;
 
-    if (siteConfig.DEBUG) {
-      console.log(`[postMetadata] resolved commit ${gitInfo.hash.slice(0, 7)} repoURL=${repoURL || 'none'}`);
-    }
+    log.debug(`resolved commit ${gitInfo.hash.slice(0, 7)} repoURL=${repoURL || 'none'}`, repoRelativePaths[0]);
 
     return {
       commitHash: gitInfo.hash.slice(0, 7),
@@ -131,8 +239,19 @@ function readGitInfo(repoRelativePaths: string[]): Omit<PostComputedMetadata, 't
   }
 }
 
-function getGitInfo(repoRelativePath: string, legacyPath: string): Omit<PostComputedMetadata, 'title' | 'originalDirectory'> {
-  return readGitInfo([repoRelativePath, legacyPath]);
+function getGitInfo(filePath: string, legacyPath: string): Omit<PostComputedMetadata, 'title' | 'originalDirectory' | 'lastModified'> {
+  const repository = findRepository(dirname(filePath));
+  if (!repository) return {};
+
+  const repoRelativePath = relative(repository.dir, realpathSync(filePath)).split(sep).join('/');
+  const editURL = repository.url
+    ? `${repository.url}/edit/${repository.branch ?? 'main'}/${repoRelativePath.split('/').map(encodeURIComponent).join('/')}`
+    : undefined;
+
+  return {
+    ...readGitInfo(repository, [repoRelativePath, legacyPath]),
+    editURL,
+  };
 }
 
 function walk(dir: string, files: string[]): void {
@@ -141,33 +260,62 @@ function walk(dir: string, files: string[]): void {
     const fullPath = join(dir, entry);
     const stat = statSync(fullPath);
     if (stat.isDirectory()) {
-      walk(fullPath, files);
-    } else if (entry.endsWith('.md')) {
+      if (!isExcludedPath(relative(process.cwd(), fullPath).split(sep).join('/'))) walk(fullPath, files);
+    } else if (entry.endsWith('.md') && !entry.startsWith('_')) {
       files.push(fullPath);
     }
   }
 }
 
-function buildCache(): Map<string, PostComputedMetadata> {
-  const map = new Map<string, PostComputedMetadata>();
+function listRootFiles(root: string): string[] {
+  const rootPath = join(process.cwd(), root);
   const files: string[] = [];
-  walk(BLOG_ROOT, files);
+  if (existsSync(rootPath)) walk(rootPath, files);
+  return files;
+}
 
-  for (const filePath of files) {
-    const rel = relative(BLOG_ROOT, filePath).split(sep).join('/');
-    const repoRel = `src/content/blog/${rel}`;
-    const legacyRel = `md/blog/${rel}`;
-    const id = toEntryId(rel);
-    const pathParts = rel.split('/');
-    const fileName = pathParts[pathParts.length - 1] || '';
-    const title = fileName.replace(/\.md$/, '');
-    const originalDirectory = pathParts.length > 1 ? pathParts[pathParts.length - 2] : undefined;
+function listContentFiles(): string[] {
+  return CONTENT_ROOTS.flatMap(listRootFiles);
+}
 
-    map.set(id, {
-      title,
-      originalDirectory,
-      ...getGitInfo(repoRel, legacyRel),
-    });
+function buildCache(): Map<string, PostComputedMetadata> {
+  const map = new Map<string, PostComputedMetadata>();
+
+  for (const root of CONTENT_ROOTS) {
+    const rootPath = join(process.cwd(), root);
+    for (const filePath of listRootFiles(root)) {
+      // A file inside a nested root belongs to the most specific one, as it
+      // does in the content layer.
+      const projectPath = relative(process.cwd(), filePath).split(sep).join('/');
+      if (splitContentPath(projectPath)?.root !== root) continue;
+
+      const rel = relative(rootPath, filePath).split(sep).join('/');
+      const legacyRel = `md/blog/${rel}`;
+      // Same id the content layer assigns, so page paths map back to sources.
+      const source = readFileSync(filePath, 'utf-8');
+      const id = getEntryId(rel, { slug: readSlug(source) });
+      const pathParts = normalizeName(rel).split('/');
+      const fileName = pathParts[pathParts.length - 1] || '';
+      const title = splitDatedFileName(fileName.replace(/\.md$/, '')).name;
+      const originalDirectory = pathParts.length > 1 ? pathParts[pathParts.length - 2] : undefined;
+
+      // Colliding ids resolve to the earliest root, matching withRootPriority.
+      if (map.has(id)) continue;
+
+      const gitInfo = getGitInfo(filePath, legacyRel);
+
+      map.set(id, {
+        title,
+        relativePath: rel,
+        filePath,
+        originalDirectory,
+        path: getPostPath(id, readOutputPath(source)),
+        ...gitInfo,
+        // Exported content and CI tarballs have no history; fall back to the
+        // file's own modification time rather than dropping the date.
+        lastModified: gitInfo.commitDate ?? statSync(filePath).mtime.toISOString(),
+      });
+    }
   }
 
   return map;
@@ -183,3 +331,68 @@ function getCache(): Map<string, PostComputedMetadata> {
 export function getPostComputedMetadataById(id: string): PostComputedMetadata | undefined {
   return getCache().get(id);
 }
+
+// Look up a post's last modification time from its page path, for sitemap lastmod.
+export function getLastModifiedByPath(pathname: string): Date | undefined {
+  const path = withoutBase(pathname).replace(/\/index\.html$/, '/');
+  const lastModified = Array.from(getCache().values()).find(metadata => metadata.path === path)?.lastModified;
+  return lastModified ? new Date(lastModified) : undefined;
+}
+
+// [[target]] or [[target|Custom Text]]
+export const WIKI_LINK = /\[\[([^\]|]+?)(?:\|([^\]]+))?\]\]/g;
+
+// Resolve the target of a `[[wiki link]]` to a post id. Targets name a post
+// by its file name or path without `.md` (`My Post`, `Nim/My Post`) or by its
+// id. A full match wins over a file name that only matches at the end of the
+// path.
+export function resolveWikiLink(target: string): string | undefined {
+  let key: string;
+  try {
+    key = toEntryId(`${target.replace(/\.md$/, '')}.md`);
+  } catch {
+    // Nothing a post could be named after, like [[???]].
+    return undefined;
+  }
+  const entries = [...getCache().entries()];
+
+  const exact = entries.find(([id, metadata]) => id === key || toEntryId(metadata.relativePath) === key);
+  if (exact) return exact[0];
+
+  const partial = entries.find(([id, metadata]) => id.endsWith(`/${key}`) || toEntryId(metadata.relativePath).endsWith(`/${key}`));
+  return partial?.[0];
+}
+
+let backlinks: Map<string, string[]> | null = null;
+
+// Scan every post's source for wiki links to other posts. Code blocks are
+// skipped so examples of the syntax don't count as links.
+function buildBacklinks(): Map<string, string[]> {
+  const map = new Map<string, string[]>();
+
+  for (const [id, metadata] of getCache()) {
+    const source = readFileSync(metadata.filePath, 'utf-8')
+      .replace(/^(```|~~~)[\s\S]*?^\1/gm, '')
+      .replace(/`[^`\n]*`/g, '');
+
+    for (const [, target] of source.matchAll(WIKI_LINK)) {
+      const page = target.split('#')[0].trim();
+      const linked = page ? resolveWikiLink(page) : undefined;
+      if (!linked || linked === id) continue;
+
+      const sources = map.get(linked) ?? [];
+      if (!sources.includes(id)) sources.push(id);
+      map.set(linked, sources);
+    }
+  }
+
+  return map;
+}
+
+// Ids of the posts that link to a post with wiki links.
+export function getBacklinks(id: string): string[] {
+  if (!backlinks) {
+    backlinks = buildBacklinks();
+  }
+  return backlinks.get(id) ?? [];
+}
@@ -1,6 +1,8 @@
+import siteConfig from '../../site.config.mjs';
+
 export function calculateReadingTime(content) {
-  const wordsPerMinute = 200;
+  const wordsPerMinute = siteConfig.READING_SPEED;
   const words = content.trim().split(/\s+/).length;
   const minutes = Math.ceil(words / wordsPerMinute);
-  return `~${minutes} min read`;
+  return siteConfig.READING_TIME_FORMAT.replace('{minutes}', String(minutes));
 }
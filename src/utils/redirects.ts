@@ -0,0 +1,50 @@
+import { existsSync, readFileSync } from 'fs';
+import { join } from 'path';
+import { parseFrontmatter } from '@astrojs/markdown-remark';
+import siteConfig from '../../site.config.mjs';
+
+export interface Redirect {
+  from: string;
+  to: string;
+  status: number;
+}
+
+const STATUSES = [301, 302, 307, 308];
+
+let redirects: Redirect[] | undefined;
+
+// Redirects listed in REDIRECTS_FILE, for moving whole sections at once:
+//
+//   /old/path/: /blog/new-path/
+//   /notes/*: { to: /blog/notes/:splat, status: 302 }
+//
+// The file is optional. It is parsed with the same YAML parser as post
+// frontmatter, so no separate YAML dependency is needed.
+export function getRedirects(): Redirect[] {
+  if (redirects) return redirects;
+
+  const path = join(process.cwd(), siteConfig.REDIRECTS_FILE);
+  if (!existsSync(path)) return (redirects = []);
+
+  const { frontmatter } = parseFrontmatter(`---\n${readFileSync(path, 'utf-8')}\n---\n`);
+  redirects = Object.entries(frontmatter).map(([from, value]) => {
+    const { to, status = 301 } = (typeof value === 'string' ? { to: value } : value ?? {}) as { to?: unknown; status?: unknown };
+    if (!from.startsWith('/')) {
+      throw new Error(`${siteConfig.REDIRECTS_FILE}: "${from}" must be a path starting with /`);
+    }
+    if (typeof to !== 'string' || !to) {
+      throw new Error(`${siteConfig.REDIRECTS_FILE}: "${from}" has no target`);
+    }
+    if (typeof status !== 'number' || !STATUSES.includes(status)) {
+      throw new Error(`${siteConfig.REDIRECTS_FILE}: "${from}" has status ${status}, expected one of ${STATUSES.join(', ')}`);
+    }
+    return { from, to, status };
+  });
+  return redirects;
+}
+
+// Redirects a static page can stand in for: exact paths only, since a
+// page can't match a wildcard.
+export function getStubRedirects(): Redirect[] {
+  return getRedirects().filter(redirect => !redirect.from.includes('*'));
+}
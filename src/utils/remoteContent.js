@@ -0,0 +1,140 @@
+import { execFileSync } from 'child_process';
+import { createHash } from 'crypto';
+import { existsSync, mkdirSync, readFileSync, readdirSync, rmSync } from 'fs';
+import { isAbsolute, join, relative, resolve } from 'path';
+import { createLogger } from './log.js';
+import { OFFLINE, request } from './http.js';
+import { writeFileAtomic } from './writeFileAtomic.js';
+
+const log = createLogger('remoteContent');
+
+const ARCHIVE_CACHE_DIR = join(process.cwd(), 'node_modules/.cache/krea.to/archives');
+// Written into an extracted archive's root, to tell whether it is current.
+const ARCHIVE_STAMP = '.krea-archive.json';
+
+function git(args, cwd) {
+  return execFileSync('git', args, { cwd, encoding: 'utf-8', stdio: ['ignore', 'pipe', 'pipe'] }).trim();
+}
+
+function isCommitHash(ref) {
+  return /^[0-9a-f]{40}$/i.test(ref);
+}
+
+// Check out `ref` of a git repository into `dir`, cloning it the first time.
+// The full history is kept, so posts get their dates from their commits as
+// local ones do. A commit hash already checked out needs no network at all.
+function syncGit({ git: url, ref = 'HEAD' }, dir) {
+  if (isOccupied(dir) && !existsSync(join(dir, '.git'))) {
+    throw new Error(`${dir} already has files but isn't a clone; remove it or pick another root`);
+  }
+  if (existsSync(join(dir, '.git'))) {
+    if (isCommitHash(ref) && git(['rev-parse', 'HEAD'], dir) === ref.toLowerCase()) return;
+    if (OFFLINE) {
+      log.warn(`offline, using the current checkout instead of ${ref}`, dir);
+      return;
+    }
+    git(['remote', 'set-url', 'origin', url], dir);
+  } else {
+    if (OFFLINE) throw new Error(`offline and ${url} has never been cloned to ${dir}`);
+    log.debug(`cloning ${url}`, dir);
+    git(['clone', '--quiet', '--no-checkout', url, dir]);
+  }
+
+  git(['fetch', '--quiet', 'origin', ref], dir);
+  git(['checkout', '--quiet', '--force', '--detach', 'FETCH_HEAD'], dir);
+  log.debug(`checked out ${ref} (${git(['rev-parse', '--short', 'HEAD'], dir)})`, dir);
+}
+
+// Whether `dir` exists and has anything in it.
+function isOccupied(dir) {
+  try {
+    return readdirSync(dir).length > 0;
+  } catch {
+    return false;
+  }
+}
+
+// A source's `root` as an absolute path. It has to be a directory of its
+// own inside the project: syncing replaces its contents, so the project
+// itself, a directory holding it or anything outside it is refused.
+function resolveRemoteRoot(root) {
+  const project = process.cwd();
+  const dir = resolve(project, root);
+  const rel = relative(project, dir);
+  if (!rel || isAbsolute(rel) || rel.split(/[\\/]/)[0] === '..') {
+    throw new Error(`remote content root "${root}" must be a directory inside the project`);
+  }
+  return dir;
+}
+
+function sha256(data) {
+  return createHash('sha256').update(data).digest('hex');
+}
+
+// Download a .tar.gz archive and unpack it into `dir`, dropping the single
+// top-level directory archives from GitHub and the like have. With `sha256`
+// the archive is pinned: a download with another hash fails the build, and
+// the verified archive is cached so later builds don't download it again.
+async function syncArchive({ archive: url, sha256: pinned }, dir) {
+  const stampPath = join(dir, ARCHIVE_STAMP);
+  let stamp;
+  try {
+    stamp = JSON.parse(readFileSync(stampPath, 'utf-8'));
+  } catch {
+    stamp = undefined;
+  }
+  if (pinned && stamp?.url === url && stamp?.sha256 === pinned) return;
+
+  const cachePath = pinned && join(ARCHIVE_CACHE_DIR, `${pinned}.tar.gz`);
+  let data = cachePath && existsSync(cachePath) ? readFileSync(cachePath) : undefined;
+  if (!data) {
+    if (OFFLINE) {
+      if (stamp) {
+        log.warn(`offline, using the archive unpacked from ${stamp.url}`, dir);
+        return;
+      }
+      throw new Error(`offline and ${url} has never been downloaded`);
+    }
+    const response = await request(url);
+    if (!response.ok) throw new Error(`downloading ${url} failed with ${response.status}`);
+    data = Buffer.from(await response.arrayBuffer());
+  }
+
+  const hash = sha256(data);
+  if (pinned && hash !== pinned) {
+    throw new Error(`${url} has sha256 ${hash}, expected ${pinned}`);
+  }
+  if (!pinned && stamp?.url === url && stamp?.sha256 === hash) return;
+  // Only ever wipe a directory we unpacked ourselves.
+  if (isOccupied(dir) && !stamp) {
+    throw new Error(`${dir} already has files but wasn't unpacked from an archive; remove it or pick another root`);
+  }
+  if (cachePath) writeFileAtomic(cachePath, data);
+
+  rmSync(dir, { recursive: true, force: true });
+  mkdirSync(dir, { recursive: true });
+  execFileSync('tar', ['-xzf', '-', '-C', dir, '--strip-components=1'], { input: data });
+  writeFileAtomic(stampPath, JSON.stringify({ url, sha256: hash }));
+  log.debug(`unpacked ${url}`, dir);
+}
+
+// Fetch the REMOTE_CONTENT sources into their `root` directories before the
+// content layer reads them. Each source is either
+//
+//   { root: 'remote/posts', git: 'https://…/posts.git', ref: 'v1.2' }
+//   { root: 'remote/notes', archive: 'https://…/notes.tar.gz', sha256: '…' }
+//
+// where `ref` is a branch, tag or commit hash (a commit hash pins the content
+// and skips fetching once checked out) and `sha256` pins an archive.
+export async function syncRemoteContent(sources) {
+  for (const source of sources) {
+    const dir = resolveRemoteRoot(source.root);
+    if (source.git) {
+      syncGit(source, dir);
+    } else if (source.archive) {
+      await syncArchive(source, dir);
+    } else {
+      throw new Error(`remote content source for ${source.root} needs a \`git\` or \`archive\` URL`);
+    }
+  }
+}
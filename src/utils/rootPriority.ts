@@ -0,0 +1,48 @@
+import type { Loader } from 'astro/loaders';
+import { CONTENT_ROOTS, splitContentPath } from './contentPaths';
+
+function getRootIndex(filePath: string | undefined): number {
+  const root = filePath ? splitContentPath(filePath)?.root : undefined;
+  return root ? CONTENT_ROOTS.indexOf(root) : CONTENT_ROOTS.length;
+}
+
+// When posts from different content roots end up with the same id, the one
+// from the root listed first in CONTENT_ROOTS wins, whatever order the files
+// are loaded in. Collisions are always reported.
+export function withRootPriority(loader: Loader): Loader {
+  return {
+    ...loader,
+    load: (context) => {
+      const owners = new Map<string, string | undefined>();
+
+      const set: typeof context.store.set = (entry) => {
+        const owner = owners.get(entry.id);
+        if (owner !== undefined && owner !== entry.filePath) {
+          const keepExisting = getRootIndex(owner) <= getRootIndex(entry.filePath);
+          const [winner, loser] = keepExisting ? [owner, entry.filePath] : [entry.filePath, owner];
+          context.logger.warn(`${loser} has the same id as ${winner} ("${entry.id}"), using ${winner}`);
+          if (keepExisting) return false;
+        }
+
+        owners.set(entry.id, entry.filePath);
+        return context.store.set(entry);
+      };
+
+      const remove: typeof context.store.delete = (id) => {
+        owners.delete(id);
+        return context.store.delete(id);
+      };
+
+      const store = new Proxy(context.store, {
+        get: (target, property) => {
+          if (property === 'set') return set;
+          if (property === 'delete') return remove;
+          const value = Reflect.get(target, property, target);
+          return typeof value === 'function' ? value.bind(target) : value;
+        },
+      });
+
+      return loader.load({ ...context, store });
+    },
+  };
+}
@@ -0,0 +1,46 @@
+import { rmSync } from 'fs';
+import { resolve } from 'path';
+import type { Loader } from 'astro/loaders';
+import { writeFileAtomic } from './writeFileAtomic.js';
+
+// Hold back posts dated in the future, so they appear with the first build
+// after their date. The date of the next held-back post is logged and, with
+// `hintFile`, written there as an ISO timestamp for CI to schedule that
+// build; the file is removed when nothing is waiting.
+export function withSchedule(loader: Loader, { hintFile }: { hintFile?: string } = {}): Loader {
+  return {
+    ...loader,
+    load: async (context) => {
+      const now = Date.now();
+      let next: Date | undefined;
+
+      const set: typeof context.store.set = (entry) => {
+        const date = (entry.data as { date?: Date }).date;
+        if (date && date.valueOf() > now) {
+          context.logger.debug(`holding back "${entry.id}" until ${date.toISOString()}`);
+          if (!next || date < next) next = date;
+          context.store.delete(entry.id);
+          return false;
+        }
+        return context.store.set(entry);
+      };
+
+      const store = new Proxy(context.store, {
+        get: (target, property) => {
+          if (property === 'set') return set;
+          const value = Reflect.get(target, property, target);
+          return typeof value === 'function' ? value.bind(target) : value;
+        },
+      });
+
+      await loader.load({ ...context, store });
+
+      if (next) context.logger.info(`next scheduled post: ${next.toISOString()}`);
+      if (hintFile) {
+        const path = resolve(process.cwd(), hintFile);
+        if (next) writeFileAtomic(path, `${next.toISOString()}\n`);
+        else rmSync(path, { force: true });
+      }
+    },
+  };
+}
@@ -0,0 +1,39 @@
+import siteConfig from '../../site.config.mjs';
+
+export interface ShareLinks {
+  mastodon: string;
+  bluesky: string;
+  email: string;
+  copy: string;
+}
+
+const SYNDICATION_LABELS: Record<string, string> = {
+  'dev.to': 'dev.to',
+  'medium.com': 'Medium',
+  'news.ycombinator.com': 'Hacker News',
+  'lobste.rs': 'Lobsters',
+  'reddit.com': 'Reddit',
+  'bsky.app': 'Bluesky',
+};
+
+// Human-readable name for a syndication URL. Unknown hosts (including
+// Mastodon instances, which can't be told apart by name) fall back to the
+// host name itself.
+export function getSyndicationLabel(url: string): string {
+  const host = new URL(url).hostname.replace(/^www\./, '');
+  const known = Object.keys(SYNDICATION_LABELS).find(domain => host === domain || host.endsWith(`.${domain}`));
+  return known ? SYNDICATION_LABELS[known] : host;
+}
+
+// Share targets for a page, built from its canonical URL and title so themes
+// don't need third-party share scripts.
+export function getShareLinks(url: string, title: string): ShareLinks {
+  const text = encodeURIComponent(`${title} ${url}`);
+
+  return {
+    mastodon: `https://${siteConfig.MASTODON_SHARE_INSTANCE}/share?text=${text}`,
+    bluesky: `https://bsky.app/intent/compose?text=${text}`,
+    email: `mailto:?subject=${encodeURIComponent(title)}&body=${encodeURIComponent(url)}`,
+    copy: url,
+  };
+}
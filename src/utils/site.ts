@@ -0,0 +1,139 @@
+import { createHash } from 'crypto';
+import { getCollection } from 'astro:content';
+import type { CollectionEntry } from 'astro:content';
+import {
+  compareTags, getCategories, getPostAuthors, getPostDate, getPostSummary, getPostTitle, getPostURL, getReadTime, getTagInfo,
+  sortPostsByDate, type Category, type TagInfo,
+} from './content';
+import { getPostComputedMetadataById } from './postMetadata';
+import { getAllTerms, type Term } from './taxonomies';
+import { BASE_PATH, pageURL, withBase } from './urls.js';
+import siteConfig from '../../site.config.mjs';
+
+export interface MenuItem {
+  name: string;
+  url: string;
+}
+
+// A post as listings show it, with its metadata already worked out.
+export interface SitePost {
+  id: string;
+  title: string;
+  url: string;
+  description?: string;
+  // See getPostSummary.
+  summary?: string;
+  date?: Date;
+  updated?: Date;
+  authors: string[];
+  tags: string[];
+  readTime?: string;
+  commitHash?: string;
+  commitURL?: string;
+  entry: CollectionEntry<'blog'>;
+}
+
+// Site-wide data every page can render from (navigation, footers, tag
+// clouds) without loading it itself. Pages get it as `Astro.locals.site`.
+export interface Site {
+  title: string;
+  description: string;
+  // SITE_URL including BASE_PATH, without a trailing slash.
+  url: string;
+  author: string;
+  // MENUS from site.config.mjs, with their links resolved.
+  menus: Record<string, MenuItem[]>;
+  // Every post, newest first.
+  posts: SitePost[];
+  sections: Category[];
+  // In tag list order: by `order` from tags.yaml, then alphabetically.
+  tags: Array<{ tag: string; count: number; info: TagInfo }>;
+  // Terms of every taxonomy (see src/utils/taxonomies.ts), by taxonomy.
+  taxonomies: Record<string, Term[]>;
+  buildTime: Date;
+  // PARAMS from site.config.mjs, for templates' own settings.
+  params: Record<string, unknown>;
+}
+
+function resolveMenus(menus: Record<string, MenuItem[]>): Record<string, MenuItem[]> {
+  return Object.fromEntries(Object.entries(menus).map(([name, items]) => [
+    name,
+    items.map(item => ({ name: item.name, url: pageURL(withBase(item.url)) })),
+  ]));
+}
+
+function toSitePost(entry: CollectionEntry<'blog'>): SitePost {
+  const computed = getPostComputedMetadataById(entry.id);
+  return {
+    id: entry.id,
+    title: getPostTitle(entry),
+    url: getPostURL(entry),
+    description: entry.data.description,
+    summary: getPostSummary(entry),
+    date: getPostDate(entry),
+    updated: entry.data.updated ?? (computed?.lastModified ? new Date(computed.lastModified) : undefined),
+    authors: getPostAuthors(entry),
+    tags: entry.data.tags,
+    readTime: getReadTime(entry),
+    commitHash: entry.data.commitHash ?? computed?.commitHash,
+    commitURL: siteConfig.SHOW_COMMIT_INFO ? computed?.commitURL : undefined,
+    entry,
+  };
+}
+
+async function loadSite(): Promise<Site> {
+  const posts = sortPostsByDate(await getCollection('blog'));
+  const tagCounts = new Map<string, number>();
+  for (const post of posts) {
+    for (const tag of post.data.tags) tagCounts.set(tag, (tagCounts.get(tag) || 0) + 1);
+  }
+  const tags = await Promise.all(
+    Array.from(tagCounts, async ([tag, count]) => ({ tag, count, info: await getTagInfo(tag) })),
+  );
+
+  return {
+    title: siteConfig.TITLE,
+    description: siteConfig.FEED_DESCRIPTION,
+    url: `${siteConfig.SITE_URL.replace(/\/+$/, '')}${BASE_PATH}`,
+    author: siteConfig.DEFAULT_AUTHOR,
+    menus: resolveMenus(siteConfig.MENUS),
+    posts: posts.map(toSitePost),
+    sections: await getCategories(),
+    tags: tags.sort(compareTags),
+    taxonomies: await getAllTerms(),
+    buildTime: new Date(),
+    params: siteConfig.PARAMS,
+  };
+}
+
+// A fingerprint of the collections the site is built from. The content
+// layer gives entries a digest of their data and body; any entry without
+// one is fingerprinted by its contents instead.
+async function getContentRevision(): Promise<string> {
+  const hash = createHash('sha256');
+  for (const collection of ['blog', 'sections', 'tags'] as const) {
+    for (const entry of await getCollection(collection)) {
+      const { digest } = entry as { digest?: string };
+      hash.update(`${collection}/${entry.id}\0${digest ?? JSON.stringify([entry.data, 'body' in entry ? entry.body : ''])}\0`);
+    }
+  }
+  return hash.digest('hex');
+}
+
+let site: Promise<Site> | undefined;
+let siteRevision: string | undefined;
+
+// Built once per build. The dev server builds it again when the content
+// store has changed since, so edits to posts show up without redoing the
+// work for every request.
+export async function getSite(): Promise<Site> {
+  if (import.meta.env.DEV) {
+    const revision = await getContentRevision();
+    if (revision !== siteRevision) {
+      siteRevision = revision;
+      site = loadSite();
+    }
+  }
+  site ??= loadSite();
+  return site;
+}
@@ -0,0 +1,92 @@
+import { readFileSync } from 'fs';
+import siteConfig from '../../site.config.mjs';
+import { DEFAULT_THEME, resolvePublicFile } from './themes';
+
+const WIDTH = 1200;
+const HEIGHT = 630;
+const TITLE_SIZE = 64;
+// Rough characters per line at TITLE_SIZE; good enough for wrapping without
+// measuring text.
+const TITLE_LINE_LENGTH = 30;
+const TITLE_MAX_LINES = 4;
+
+interface CardStyle {
+  background: string;
+  text: string;
+  accent: string;
+  // Path below public/.
+  image?: string;
+}
+
+// Colors and wallpaper of the default theme, so cards look like the site.
+function getCardStyle(): CardStyle {
+  const css = readFileSync(resolvePublicFile(`css/themes/${DEFAULT_THEME}.css`)!, 'utf-8');
+  const variable = (name: string) => css.match(new RegExp(`--${name}:\\s*([^;]+);`))?.[1].trim();
+  // Theme stylesheets point at ../../assets/ from public/css/themes.
+  const wallpaper = variable('background-image')?.match(/url\(['"]?(?:\.\.\/)*([^'")]+)['"]?\)/)?.[1];
+
+  return {
+    background: variable('bg-color') ?? '#1a1a1a',
+    text: variable('text-color') ?? '#ffffff',
+    accent: variable('accent-color') ?? '#ffffff',
+    image: siteConfig.OG_CARD_BACKGROUND || wallpaper,
+  };
+}
+
+function escapeXml(text: string): string {
+  return text.replace(/[<>&"']/g, (char) => `&#${char.charCodeAt(0)};`);
+}
+
+function wrapTitle(title: string): string[] {
+  const lines: string[] = [];
+  let line = '';
+  for (const word of title.split(/\s+/)) {
+    if (line && (line + ' ' + word).length > TITLE_LINE_LENGTH) {
+      lines.push(line);
+      line = word;
+    } else {
+      line = line ? `${line} ${word}` : word;
+    }
+  }
+  if (line) lines.push(line);
+
+  if (lines.length > TITLE_MAX_LINES) {
+    lines.length = TITLE_MAX_LINES;
+    lines[TITLE_MAX_LINES - 1] += '…';
+  }
+  return lines;
+}
+
+// A 1200×630 PNG preview for a post: its title, the site name and the date
+// over the default theme's wallpaper. Needs sharp; returns undefined without it.
+export async function renderSocialCard(title: string, date?: Date): Promise<Buffer | undefined> {
+  let sharp;
+  try {
+    sharp = (await import('sharp')).default;
+  } catch {
+    return undefined;
+  }
+
+  const style = getCardStyle();
+  const lines = wrapTitle(title);
+  const titleTop = (HEIGHT - lines.length * TITLE_SIZE * 1.2) / 2 + TITLE_SIZE;
+  const footer = [siteConfig.TITLE, date?.toLocaleDateString('en-US', { year: 'numeric', month: 'long', day: 'numeric' })]
+    .filter(Boolean)
+    .join(' · ');
+
+  const svg = `<svg xmlns="http://www.w3.org/2000/svg" width="${WIDTH}" height="${HEIGHT}">
+  <rect width="100%" height="100%" fill="${style.background}" fill-opacity="${style.image ? 0.75 : 1}"/>
+  <rect x="80" y="${titleTop - TITLE_SIZE - 40}" width="120" height="8" fill="${style.accent}"/>
+  <text font-family="sans-serif" font-weight="bold" font-size="${TITLE_SIZE}" fill="${style.text}">
+    ${lines.map((line, index) => `<tspan x="80" y="${titleTop + index * TITLE_SIZE * 1.2}">${escapeXml(line)}</tspan>`).join('')}
+  </text>
+  <text x="80" y="${HEIGHT - 70}" font-family="sans-serif" font-size="32" fill="${style.accent}">${escapeXml(footer)}</text>
+</svg>`;
+
+  const imagePath = style.image && resolvePublicFile(style.image);
+  const card = imagePath
+    ? sharp(imagePath).resize(WIDTH, HEIGHT, { fit: 'cover' })
+    : sharp({ create: { width: WIDTH, height: HEIGHT, channels: 3, background: style.background } });
+
+  return card.composite([{ input: Buffer.from(svg) }]).png().toBuffer();
+}
@@ -0,0 +1,74 @@
+import type { CollectionEntry } from 'astro:content';
+import { getCategories, getPostCategorySlug, getPostTitle, getPostURL, hasCategoryIndex } from './content';
+import { getTermURL } from './taxonomies';
+import { blogURL, withBase } from './urls.js';
+import siteConfig from '../../site.config.mjs';
+
+// schema.org JSON-LD for the pages, built from the same metadata the
+// templates show so the two can't drift apart.
+
+function absolute(path: string): string {
+  return new URL(path, siteConfig.SITE_URL).href;
+}
+
+export interface ArticleData {
+  title: string;
+  description?: string;
+  authors?: string[];
+  date?: Date;
+  lastModified?: Date;
+  // Path or URL of the preview image.
+  image?: string;
+}
+
+export function getArticleStructuredData(entry: CollectionEntry<'blog'>, article: ArticleData): object {
+  const url = absolute(getPostURL(entry));
+  return {
+    "@context": "https://schema.org",
+    "@type": "BlogPosting",
+    "headline": article.title,
+    ...(article.description && { "description": article.description }),
+    ...(article.authors && article.authors.length > 0 && { "author": article.authors.map(name => ({ "@type": "Person", "name": name, "url": absolute(getTermURL('authors', name)) })) }),
+    ...(article.date && { "datePublished": article.date.toISOString() }),
+    ...(article.lastModified && { "dateModified": article.lastModified.toISOString() }),
+    ...(article.image && { "image": absolute(article.image) }),
+    ...(entry.data.tags.length > 0 && { "keywords": entry.data.tags.join(', ') }),
+    "publisher": { "@type": "Organization", "name": siteConfig.TITLE, "url": absolute(withBase('/')) },
+    "mainEntityOfPage": { "@type": "WebPage", "@id": url },
+    "url": url
+  };
+}
+
+function breadcrumbList(items: Array<{ name: string; path: string }>): object {
+  return {
+    "@context": "https://schema.org",
+    "@type": "BreadcrumbList",
+    "itemListElement": items.map((item, index) => ({
+      "@type": "ListItem",
+      "position": index + 1,
+      "name": item.name,
+      "item": absolute(item.path)
+    }))
+  };
+}
+
+// Blog → category → post, with the category only when it has an index page.
+export async function getPostBreadcrumbs(entry: CollectionEntry<'blog'>): Promise<object> {
+  const items = [{ name: siteConfig.TITLE, path: blogURL() }];
+
+  const slug = getPostCategorySlug(entry);
+  const category = slug ? (await getCategories()).find(category => category.slug === slug) : undefined;
+  if (category && hasCategoryIndex(category)) {
+    items.push({ name: category.name, path: blogURL(`${category.slug}/`) });
+  }
+
+  items.push({ name: getPostTitle(entry), path: getPostURL(entry) });
+  return breadcrumbList(items);
+}
+
+export function getCategoryBreadcrumbs(category: { slug: string; name: string }): object {
+  return breadcrumbList([
+    { name: siteConfig.TITLE, path: blogURL() },
+    { name: category.name, path: blogURL(`${category.slug}/`) },
+  ]);
+}
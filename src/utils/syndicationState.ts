@@ -0,0 +1,32 @@
+import { existsSync, readFileSync } from 'fs';
+import { join } from 'path';
+import siteConfig from '../../site.config.mjs';
+import { writeFileAtomic } from './writeFileAtomic.js';
+
+// Where posts were syndicated to by the build itself, keyed by entry id.
+// Commit this file so later builds keep linking to the copies.
+export type SyndicationState = Record<string, string[]>;
+
+const STATE_PATH = join(process.cwd(), siteConfig.SYNDICATION_STATE_FILE);
+
+export function hasSyndicationState(): boolean {
+  return existsSync(STATE_PATH);
+}
+
+export function readSyndicationState(): SyndicationState {
+  try {
+    return JSON.parse(readFileSync(STATE_PATH, 'utf-8'));
+  } catch {
+    return {};
+  }
+}
+
+export function writeSyndicationState(state: SyndicationState): void {
+  writeFileAtomic(STATE_PATH, `${JSON.stringify(state, null, 2)}\n`);
+}
+
+const STATE = readSyndicationState();
+
+export function getSyndicatedURLs(id: string): string[] {
+  return STATE[id] ?? [];
+}
@@ -0,0 +1,152 @@
+import { getCollection } from 'astro:content';
+import type { CollectionEntry } from 'astro:content';
+import { getCategories, getPostAuthors, getPostCategorySlug, getPostDate, getTagInfo, sortPostsByDate } from './content';
+import { slugifySegment } from './contentPaths';
+import { blogURL } from './urls.js';
+import siteConfig from '../../site.config.mjs';
+
+// Ways of grouping posts by their metadata. Every taxonomy gets a page per
+// term at /blog/<path>/<term>/ listing its posts, and a feed next to it when
+// TAXONOMY_FEEDS names it. Adding one takes a metadata key in the blog
+// schema and an entry here.
+
+export interface TaxonomyDefinition {
+  // URL directory below the blog, e.g. 'tags' for /blog/tags/<term>/.
+  path: string;
+  // Heading of a term's page, followed by the term's name.
+  heading: string;
+  // The terms a post belongs to, as written in its metadata.
+  getTerms(post: CollectionEntry<'blog'>): string[];
+  // URL form of a term. Defaults to slugifySegment.
+  slugify?(term: string): string;
+  // Display name and description for a term, from a data file or the like.
+  describe?(term: string): Promise<{ name?: string; description?: string }>;
+  // Order of a term's posts. Defaults to newest first.
+  sortPosts?(posts: CollectionEntry<'blog'>[]): CollectionEntry<'blog'>[];
+  // Index page listing every term, when the taxonomy has one.
+  indexPath?: string;
+  // Term pages are generated elsewhere (categories have their own routes).
+  pages?: false;
+}
+
+export const TAXONOMIES: Record<string, TaxonomyDefinition> = {
+  tags: {
+    path: 'tags',
+    heading: 'Posts tagged with:',
+    getTerms: post => post.data.tags,
+    // Tag URLs use the tag as written.
+    slugify: tag => tag,
+    describe: getTagInfo,
+    indexPath: 'tags/',
+  },
+  categories: {
+    path: '',
+    heading: '',
+    getTerms: post => {
+      const category = getPostCategorySlug(post);
+      return category ? [category] : [];
+    },
+    slugify: slug => slug,
+    describe: async slug => (await getCategories()).find(category => category.slug === slug) ?? {},
+    pages: false,
+  },
+  series: {
+    path: 'series',
+    heading: 'Series:',
+    getTerms: post => post.data.series ? [post.data.series] : [],
+    // Reading order: by `seriesPart`, then oldest first.
+    sortPosts: posts => posts.sort((a, b) =>
+      (a.data.seriesPart ?? Infinity) - (b.data.seriesPart ?? Infinity)
+      || (getPostDate(a)?.valueOf() || 0) - (getPostDate(b)?.valueOf() || 0)),
+  },
+  authors: {
+    path: 'authors',
+    heading: 'Posts by',
+    getTerms: getPostAuthors,
+  },
+};
+
+export interface Term {
+  taxonomy: string;
+  slug: string;
+  name: string;
+  description?: string;
+  posts: CollectionEntry<'blog'>[];
+  url: string;
+  feedURL?: string;
+}
+
+export function hasTaxonomyFeed(taxonomy: string): boolean {
+  return siteConfig.TAXONOMY_FEEDS.includes(taxonomy);
+}
+
+function slugifyTerm(taxonomy: string, term: string): string {
+  return (TAXONOMIES[taxonomy].slugify ?? slugifySegment)(term);
+}
+
+// A path inside a term's directory, '' for its page.
+function termPath(taxonomy: string, term: string, file: string = ''): string {
+  const { path } = TAXONOMIES[taxonomy];
+  return blogURL(`${path ? `${path}/` : ''}${slugifyTerm(taxonomy, term)}/${file}`);
+}
+
+export function getTermURL(taxonomy: string, term: string): string {
+  return termPath(taxonomy, term);
+}
+
+// Every term of a taxonomy with its posts. Terms whose spellings slugify
+// alike are merged, under the first spelling seen.
+export async function getTerms(taxonomy: string): Promise<Term[]> {
+  const definition = TAXONOMIES[taxonomy];
+  const terms = new Map<string, Term>();
+  for (const post of sortPostsByDate(await getCollection('blog'))) {
+    for (const name of definition.getTerms(post)) {
+      const slug = slugifyTerm(taxonomy, name);
+      if (!terms.has(slug)) {
+        terms.set(slug, {
+          taxonomy,
+          slug,
+          name,
+          posts: [],
+          url: termPath(taxonomy, name),
+          feedURL: hasTaxonomyFeed(taxonomy) ? termPath(taxonomy, name, 'feed.xml') : undefined,
+        });
+      }
+      terms.get(slug)!.posts.push(post);
+    }
+  }
+
+  return Promise.all(Array.from(terms.values(), async term => {
+    const info = await definition.describe?.(term.name);
+    return {
+      ...term,
+      name: info?.name ?? term.name,
+      description: info?.description,
+      posts: definition.sortPosts?.(term.posts) ?? term.posts,
+    };
+  }));
+}
+
+// Every term of every taxonomy, for Astro.locals.site.
+export async function getAllTerms(): Promise<Record<string, Term[]>> {
+  return Object.fromEntries(await Promise.all(
+    Object.keys(TAXONOMIES).map(async taxonomy => [taxonomy, await getTerms(taxonomy)]),
+  ));
+}
+
+// Where a post sits in its series, for the series box and prev/next links.
+export async function getSeriesNavigation(entry: CollectionEntry<'blog'>): Promise<{
+  series: Term;
+  part: number;
+  previous?: CollectionEntry<'blog'>;
+  next?: CollectionEntry<'blog'>;
+} | undefined> {
+  if (!entry.data.series) return undefined;
+
+  const slug = slugifyTerm('series', entry.data.series);
+  const series = (await getTerms('series')).find(series => series.slug === slug);
+  const index = series?.posts.findIndex(post => post.id === entry.id) ?? -1;
+  if (!series || index < 0) return undefined;
+
+  return { series, part: index + 1, previous: series.posts[index - 1], next: series.posts[index + 1] };
+}
@@ -0,0 +1,84 @@
+import { existsSync, readdirSync } from 'fs';
+import { join, resolve } from 'path';
+import siteConfig from '../../site.config.mjs';
+
+// Directories laid out like public/ whose files replace or add to the
+// site's own: a `css/themes/<name>.css` adds a color scheme, a
+// `css/style.css` restyles everything. Earlier directories win.
+export const THEME_DIRS: string[] = siteConfig.THEME_DIRS.map((dir: string) => resolve(process.cwd(), dir));
+
+const PUBLIC_DIR = join(process.cwd(), 'public');
+
+// Where a file below public/ (like 'css/themes/nord.css') comes from once
+// THEME_DIRS are applied, or undefined if no directory has it.
+export function resolvePublicFile(path: string): string | undefined {
+  return [...THEME_DIRS, PUBLIC_DIR]
+    .map(dir => join(dir, path))
+    .find(file => existsSync(file));
+}
+
+// Every file of a public/ subdirectory, as paths below public/, with the
+// THEME_DIRS' files included.
+export function listPublicFiles(directory: string): string[] {
+  const files = new Set<string>();
+  for (const dir of [...THEME_DIRS, PUBLIC_DIR]) {
+    const root = join(dir, directory);
+    if (!existsSync(root)) continue;
+    for (const file of readdirSync(root, { recursive: true, withFileTypes: true })) {
+      if (file.isFile()) files.add(join(directory, file.parentPath.slice(root.length), file.name));
+    }
+  }
+  return Array.from(files).sort();
+}
+
+// Every stylesheet in css/themes is a selectable theme, so adding a theme
+// doesn't require touching the theme script.
+export const THEMES: string[] = listPublicFiles('css/themes')
+  .filter(file => file.endsWith('.css'))
+  .map(file => file.slice('css/themes/'.length, -'.css'.length));
+
+// Names shown in the theme menu; other themes show their file name.
+const THEME_NAMES: Record<string, string> = {
+  frappe: 'Frappe',
+  gruvbox: 'Gruvbox',
+  kanagawa: 'Kanagawa',
+  latte: 'Latte',
+  macchiato: 'Macchiato',
+  mocha: 'Mocha',
+  monokai: 'Monokai',
+  nord: 'Nord',
+  onedark: 'One Dark',
+  pinkie: 'Pinkie',
+  solarized: 'Solarized',
+  tokyonight: 'Tokyo Night',
+};
+
+export function getThemeName(theme: string): string {
+  return THEME_NAMES[theme] ?? theme.charAt(0).toUpperCase() + theme.slice(1);
+}
+
+// DEFAULT_THEME, or the THEME environment variable for a single build
+// (`THEME=nord bun run build`).
+export const DEFAULT_THEME: string = process.env.THEME || siteConfig.DEFAULT_THEME;
+
+// Shown instead of DEFAULT_THEME to visitors whose system prefers a light
+// color scheme, until they pick one themselves. Undefined when not set.
+export const DEFAULT_LIGHT_THEME: string | undefined = siteConfig.DEFAULT_LIGHT_THEME || undefined;
+
+for (const theme of [DEFAULT_THEME, DEFAULT_LIGHT_THEME]) {
+  if (theme !== undefined && !THEMES.includes(theme)) {
+    throw new Error(`Unknown theme "${theme}" (available: ${THEMES.join(', ')})`);
+  }
+}
+
+// Themes that should get the `light-theme` class. The light default is one
+// whatever it is.
+export const LIGHT_THEMES = ['latte'];
+if (DEFAULT_LIGHT_THEME && !LIGHT_THEMES.includes(DEFAULT_LIGHT_THEME)) {
+  LIGHT_THEMES.push(DEFAULT_LIGHT_THEME);
+}
+
+// Site path of a theme's stylesheet, without BASE_PATH.
+export function getThemeCSSPath(theme: string): string {
+  return `/css/themes/${theme}.css`;
+}
@@ -0,0 +1,41 @@
+import siteConfig from '../../site.config.mjs';
+
+// BASE_PATH as '/notes' (or '' when the site lives at the domain root).
+export const BASE_PATH = siteConfig.BASE_PATH.replace(/^\/*(?=.)/, '/').replace(/\/+$/, '');
+
+// URL directory the blog is published under, e.g. 'blog' for /blog/<post>/.
+export const BLOG_PATH = siteConfig.BLOG_PATH.replace(/^\/+|\/+$/g, '');
+
+// Pages are written as <path>/index.html, so each is reachable both as
+// <path>/ and as <path>/index.html. URL_STYLE picks the one the site links
+// to ('directory' or 'file'), so search engines see one URL per page.
+export const URL_STYLE = siteConfig.URL_STYLE === 'file' ? 'file' : 'directory';
+
+const PAGE_PATH = /^([^?#]*\/)(?:index\.html)?(?=[?#]|$)/;
+
+// Spell a page's path or URL the way URL_STYLE wants it. Anything that isn't
+// a directory or an index.html (feeds, images) is left alone.
+export function pageURL(url) {
+  return url.replace(PAGE_PATH, (_, directory) => URL_STYLE === 'file' ? `${directory}index.html` : directory);
+}
+
+// Prefix a site path like `/blog/foo/` with BASE_PATH. Anything that isn't
+// a root-relative path (full URLs, `//cdn` URLs, relative paths) is left
+// alone, so it is safe on user-supplied links.
+export function withBase(path) {
+  if (!BASE_PATH || !path.startsWith('/') || path.startsWith('//')) return path;
+  return `${BASE_PATH}${path}`;
+}
+
+// Undo withBase for a path taken from built HTML, giving the file's path
+// inside the output directory.
+export function withoutBase(path) {
+  if (!BASE_PATH || !path.startsWith(`${BASE_PATH}/`)) return path;
+  return path.slice(BASE_PATH.length);
+}
+
+// A path below the blog, like blogURL('tags/') for /<base>/blog/tags/.
+// Page paths follow URL_STYLE.
+export function blogURL(path = '') {
+  return pageURL(withBase(`/${BLOG_PATH}/${path}`));
+}
@@ -0,0 +1,19 @@
+import { mkdirSync, renameSync, rmSync, writeFileSync } from 'fs';
+import { basename, dirname, join } from 'path';
+
+// Write a file so readers only ever see the old or the new contents, never a
+// half-written file: write a uniquely named sibling, then rename it into
+// place. Parallel builds sharing a cache directory (CI matrices, a dev
+// server next to a build) otherwise corrupt each other's cache files.
+export function writeFileAtomic(path, data) {
+  mkdirSync(dirname(path), { recursive: true });
+
+  const temporary = join(dirname(path), `.${basename(path)}.${process.pid}.${Date.now()}.tmp`);
+  try {
+    writeFileSync(temporary, data);
+    renameSync(temporary, path);
+  } catch (error) {
+    rmSync(temporary, { force: true });
+    throw error;
+  }
+}